@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yourusername/tastygo/config"
+)
+
+const configUsage = `Usage: tastygo config print [--redacted] [--config file] [flags]
+
+Print the effective configuration (defaults, config file, environment and flags)
+as YAML. With --redacted, secrets such as JWT_SECRET and passwords are masked.
+Exits with status 1 and lists every error when the configuration is invalid.
+`
+
+// runConfig xử lý subcommand `config` và trả về exit code
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	// --redacted thuộc về subcommand, các flag còn lại là flag cấu hình
+	redacted := false
+	var rest []string
+	for _, arg := range args[1:] {
+		if arg == "--redacted" || arg == "-redacted" {
+			redacted = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	cfg, err := config.Load(rest)
+	var invalid *config.ValidationError
+	if err != nil && !errors.As(err, &invalid) {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return 2
+	}
+
+	if err := cfg.Write(os.Stdout, redacted); err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return 1
+	}
+	if invalid != nil {
+		fmt.Fprintln(os.Stderr, invalid)
+		return 1
+	}
+	return 0
+}
+
+// loadToolConfig nạp cấu hình cho các subcommand tiện ích (migrate, healthcheck) từ file,
+// biến môi trường và flag trong args. Các subcommand này chỉ dùng một phần cấu hình nên chỉ
+// báo lỗi validate của keys (section hoặc key đầy đủ); server vẫn báo đầy đủ khi khởi động
+func loadToolConfig(args []string, keys ...string) (*config.Config, error) {
+	cfg, err := config.Load(args)
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		if relevant := invalid.Filter(keys...); relevant != nil {
+			return nil, relevant
+		}
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// splitPositional tách tham số vị trí đứng đầu (như số bước của migrate down) khỏi các flag
+// cấu hình phía sau
+func splitPositional(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
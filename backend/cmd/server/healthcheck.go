@@ -0,0 +1,44 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// runHealthcheck gọi probe của server đang chạy trên cùng máy và trả về exit code,
+// dùng cho HEALTHCHECK trong image không có curl/wget. Cú pháp: healthcheck [path] [flags]
+func runHealthcheck(args []string) int {
+	path, args := splitPositional(args)
+	if path == "" {
+		path = "/healthz"
+	}
+
+	cfg, err := loadToolConfig(args, "app.port", "tls.cert_file", "tls.key_file")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck: %v\n", err)
+		return 1
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	scheme := "http"
+	if cfg.TLS.Enabled() {
+		// Chứng chỉ được cấp cho domain chứ không cho 127.0.0.1, và probe chỉ gọi tới chính máy này
+		scheme = "https"
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	resp, err := client.Get(fmt.Sprintf("%s://127.0.0.1:%d%s", scheme, cfg.App.Port, path))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "healthcheck: %s returned %d\n", path, resp.StatusCode)
+		return 1
+	}
+	return 0
+}
@@ -1,75 +1,449 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/yourusername/tastygo/config"
 	"github.com/yourusername/tastygo/internal/api"
 	"github.com/yourusername/tastygo/internal/auth"
+	"github.com/yourusername/tastygo/internal/buildinfo"
+	"github.com/yourusername/tastygo/internal/cache"
+	"github.com/yourusername/tastygo/internal/certs"
 	"github.com/yourusername/tastygo/internal/database"
+	"github.com/yourusername/tastygo/internal/health"
+	"github.com/yourusername/tastygo/internal/lifecycle"
 	"github.com/yourusername/tastygo/internal/logging"
+	"github.com/yourusername/tastygo/internal/mailer"
+	"github.com/yourusername/tastygo/internal/ratelimit"
+	"github.com/yourusername/tastygo/internal/repository"
 )
 
 func main() {
-	// Tải cấu hình ứng dụng
-	appConfig := config.LoadAppConfig()
-	dbConfig := config.LoadDBConfig()
-	
-	// Kiểm tra và đảm bảo JWT secret đủ mạnh
-	if len(appConfig.JWTSecret) < 32 {
-		logging.Warn("JWT_SECRET should be at least 32 characters long for security", nil)
-		if appConfig.JWTSecret == "change_this_in_production" || appConfig.JWTSecret == "" {
-			logging.Error("Using default JWT_SECRET in production is not secure!", nil)
+	// Subcommand quản lý migration: tastygo migrate up|down|status|create
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	// Subcommand cho HEALTHCHECK của Docker: tastygo healthcheck [path]
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		os.Exit(runHealthcheck(os.Args[2:]))
+	}
+
+	// In cấu hình đang có hiệu lực: tastygo config print [--redacted]
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfig(os.Args[2:]))
+	}
+
+	// Tải cấu hình từ file, biến môi trường và flag; dừng ngay và liệt kê mọi lỗi nếu không hợp lệ
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		var invalid *config.ValidationError
+		if errors.As(err, &invalid) {
+			logging.Fatal("Invalid configuration", map[string]interface{}{
+				"errors": invalid.Errors,
+			})
 		}
+		logging.Fatal("Failed to load configuration", map[string]interface{}{
+			"error": err.Error(),
+		})
 	}
+	appConfig := cfg.App
+	mailConfig := cfg.Mail
+	cacheConfig := cfg.Cache
+	rateLimitConfig := cfg.RateLimit
 
-	// Khởi tạo JWT secret
-	auth.InitJWTSecret()
+	// Cấu hình các đích ghi log; flush và đóng file log khi thoát
+	if err := setupLogging(cfg.Log); err != nil {
+		logging.Fatal("Failed to configure logging", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer logging.DefaultLogger.Close()
+
+	// Khởi tạo mailer: dùng SMTP nếu được cấu hình, ngược lại ghi email ra thư mục
+	var mail mailer.Mailer
+	if mailConfig.SMTPHost != "" {
+		mail = mailer.NewSMTPMailer(mailConfig.SMTPHost, mailConfig.SMTPPort,
+			mailConfig.SMTPUsername, mailConfig.SMTPPassword, mailConfig.From)
+	} else {
+		mail = mailer.NewFileMailer(mailConfig.Dir, mailConfig.From)
+		logging.Warn("SMTP_HOST is not set, emails will be written to disk", map[string]interface{}{
+			"dir": mailConfig.Dir,
+		})
+	}
 
 	// Khởi tạo database
-	err := database.InitDB(dbConfig.Path)
+	db, err := database.InitDB(databaseConfig(cfg))
 	if err != nil {
 		logging.Fatal("Failed to initialize database", map[string]interface{}{
+			"error":  err.Error(),
+			"driver": cfg.Database.Driver,
+		})
+	}
+
+	// Khởi tạo cache theo backend được cấu hình
+	appCache, err := cache.New(cache.Config{
+		Backend:       cacheConfig.Backend,
+		MaxEntries:    cacheConfig.MaxEntries,
+		RedisAddr:     cacheConfig.RedisAddr,
+		RedisPassword: cacheConfig.RedisPassword,
+		RedisDB:       cacheConfig.RedisDB,
+		Prefix:        cacheConfig.Prefix,
+	})
+	if err != nil {
+		logging.Fatal("Failed to initialize cache", map[string]interface{}{
+			"error":   err.Error(),
+			"backend": cacheConfig.Backend,
+		})
+	}
+
+	// Khởi tạo auth service với repository GORM
+	authConfig := auth.DefaultConfig()
+	authConfig.JWTSecret = auth.ResolveJWTSecret(appConfig.JWTSecret)
+	authConfig.FrontendURL = mailConfig.FrontendURL
+	authService := auth.NewService(repository.NewGormRepositories(db), mail, appCache, authConfig)
+
+	// Quản lý trạng thái sẵn sàng, worker nền và thứ tự shutdown
+	lc := lifecycle.NewManager()
+	if memoryCache, ok := appCache.(*cache.MemoryCache); ok {
+		lc.Go("cache-janitor", func(ctx context.Context) {
+			memoryCache.Janitor(ctx, time.Minute)
+		})
+	}
+	if redisCache, ok := appCache.(*cache.RedisCache); ok {
+		lc.OnShutdown("cache", func(ctx context.Context) error {
+			return redisCache.Close()
+		})
+	}
+
+	// Rate limit theo nhóm route
+	limiter, limiterStore, err := newRateLimiter(rateLimitConfig, cacheConfig)
+	if err != nil {
+		logging.Fatal("Failed to initialize rate limiter", map[string]interface{}{
+			"error": err.Error(),
+			"store": rateLimitConfig.Store,
+		})
+	}
+	switch store := limiterStore.(type) {
+	case *ratelimit.MemoryStore:
+		lc.Go("rate-limiter-cleanup", store.Cleanup)
+	case *ratelimit.RedisStore:
+		lc.OnShutdown("rate-limit-store", func(ctx context.Context) error {
+			return store.Close()
+		})
+	}
+	lc.OnShutdown("database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+
+	// Health check cho /readyz; các thành phần khác có thể đăng ký thêm Checker
+	migrationFS, err := database.MigrationFS(cfg.Database.Driver)
+	if err != nil {
+		logging.Fatal("Failed to load migrations", map[string]interface{}{
 			"error": err.Error(),
-			"path":  dbConfig.Path,
 		})
 	}
+	migrator, err := database.NewMigrator(db, migrationFS)
+	if err != nil {
+		logging.Fatal("Failed to load migrations", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	checks := health.NewRegistry(2 * time.Second)
+	checks.Register(
+		health.ReadinessChecker(lc),
+		health.DatabaseChecker(db),
+		health.MigrationsChecker(migrator),
+		health.WorkersChecker(lc),
+	)
+	if checker, ok := mail.(health.Checker); ok {
+		checks.Register(checker)
+	}
+	if checker, ok := appCache.(health.Checker); ok {
+		checks.Register(checker)
+	}
+	if checker, ok := limiterStore.(health.Checker); ok {
+		checks.Register(checker)
+	}
+
+	// Khởi tạo server. Cấu hình CORS đã được kiểm tra khi nạp nên Policy không lỗi
+	corsConfig, _ := cfg.CORS.Policy()
+	serverOptions := []api.Option{
+		api.WithRateLimiter(limiter),
+		api.WithTrustedProxies(cfg.App.TrustedProxyList()...),
+		api.WithCORS(corsConfig),
+		api.WithSecurityHeaders(api.SecurityHeaders{
+			HSTSMaxAge:            cfg.Security.HSTSMaxAge,
+			HSTSIncludeSubdomains: cfg.Security.HSTSIncludeSubdomains,
+			ContentSecurityPolicy: cfg.Security.ContentSecurityPolicy,
+			FrameOptions:          cfg.Security.FrameOptions,
+			ReferrerPolicy:        cfg.Security.ReferrerPolicy,
+		}),
+	}
+	// HTTPS với chứng chỉ được nạp lại khi file thay đổi; CA của client bật mutual TLS cho route nội bộ
+	var reloader *certs.Reloader
+	if cfg.TLS.Enabled() {
+		reloader, err = certs.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile, cfg.TLS.TLSVersion())
+		if err != nil {
+			logging.Fatal("Failed to load TLS certificate", map[string]interface{}{
+				"error":     err.Error(),
+				"cert_file": cfg.TLS.CertFile,
+			})
+		}
+		lc.Go("tls-reload", func(ctx context.Context) {
+			reloader.Watch(ctx, cfg.TLS.ReloadInterval)
+		})
+		if cfg.TLS.ClientCAFile != "" {
+			serverOptions = append(serverOptions, api.WithClientCertRoutes(cfg.TLS.ClientCertPrefixes()...))
+		}
+	}
+	if cfg.Session.CookieEnabled {
+		serverOptions = append(serverOptions, api.WithSessionCookies(auth.CookieConfig{
+			Domain:   cfg.Session.CookieDomain,
+			Secure:   cfg.Session.CookieSecure,
+			SameSite: cfg.Session.SameSite(),
+		}))
+	}
+	router := api.NewServer(authService, serverOptions...)
+	api.RegisterProbes(router, checks)
 
-	// Khởi tạo server
-	server := api.NewServer()
+	server := &http.Server{
+		Addr:         ":" + strconv.Itoa(appConfig.Port),
+		Handler:      router,
+		ReadTimeout:  appConfig.ReadTimeout,
+		WriteTimeout: appConfig.WriteTimeout,
+		IdleTimeout:  appConfig.IdleTimeout,
+	}
+	// /metrics chạy trên listener nội bộ riêng, không đi qua listener công khai
+	var metricsServer *http.Server
+	if cfg.Metrics.Addr != "" {
+		metricsServer = &http.Server{
+			Addr:         cfg.Metrics.Addr,
+			Handler:      api.NewMetricsServer(authService),
+			ReadTimeout:  appConfig.ReadTimeout,
+			WriteTimeout: appConfig.WriteTimeout,
+			IdleTimeout:  appConfig.IdleTimeout,
+		}
+	}
+	var redirectServer *http.Server
+	if reloader != nil {
+		server.TLSConfig = reloader.TLSConfig()
+		if cfg.TLS.RedirectPort != 0 {
+			redirectServer = &http.Server{
+				Addr:         ":" + strconv.Itoa(cfg.TLS.RedirectPort),
+				Handler:      api.HTTPSRedirectHandler(appConfig.Port),
+				ReadTimeout:  appConfig.ReadTimeout,
+				WriteTimeout: appConfig.WriteTimeout,
+				IdleTimeout:  appConfig.IdleTimeout,
+			}
+		}
+	}
 
 	// Xử lý graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		// Khởi động server
-		port := appConfig.Port
-		portStr := os.Getenv("PORT")
-		if portStr != "" {
-			port = appConfig.Port
-		}
-
 		logging.Info("Server starting", map[string]interface{}{
-			"port": port,
-			"mode": appConfig.GinMode,
+			"port":    appConfig.Port,
+			"mode":    appConfig.GinMode,
+			"tls":     reloader != nil,
+			"version": buildinfo.Get(),
 		})
-		
-		if err := server.Run(":" + strconv.Itoa(port)); err != nil {
+
+		var err error
+		if reloader != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logging.Fatal("Failed to start server", map[string]interface{}{
 				"error": err.Error(),
 			})
 		}
 	}()
+	if redirectServer != nil {
+		go func() {
+			logging.Info("HTTP to HTTPS redirect listener starting", map[string]interface{}{
+				"port": cfg.TLS.RedirectPort,
+			})
+			if err := redirectServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logging.Fatal("Failed to start redirect listener", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}()
+	}
+	if metricsServer != nil {
+		go func() {
+			logging.Info("Metrics listener starting", map[string]interface{}{
+				"addr": cfg.Metrics.Addr,
+			})
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logging.Fatal("Failed to start metrics listener", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}()
+	}
+	lc.SetReady(true)
 
 	<-quit
-	logging.Info("Shutting down server...", nil)
 
-	// Thực hiện các tác vụ cleanup nếu cần
-	// ...
+	// Báo chưa sẵn sàng trước, chờ load balancer ngừng gửi traffic rồi mới drain
+	lc.SetReady(false)
+	logging.Info("Shutting down server...", map[string]interface{}{
+		"delay":   appConfig.ShutdownDelay.String(),
+		"timeout": appConfig.ShutdownTimeout.String(),
+	})
+	time.Sleep(appConfig.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logging.Error("Server did not drain in-flight requests in time", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if redirectServer != nil {
+		redirectServer.Shutdown(ctx)
+	}
+	if metricsServer != nil {
+		metricsServer.Shutdown(ctx)
+	}
+
+	// Dừng worker nền rồi đóng database, theo thứ tự đăng ký
+	hooksCtx, cancelHooks := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+	defer cancelHooks()
+	if err := lc.Shutdown(hooksCtx); err != nil {
+		logging.Error("Server exited with shutdown errors", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	logging.Info("Server exited properly", nil)
 }
+
+// databaseConfig chuyển cấu hình sang database.Config
+func databaseConfig(cfg *config.Config) database.Config {
+	return database.Config{
+		Driver:          cfg.Database.Driver,
+		DSN:             cfg.Database.DSN,
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+		LogLevel:        cfg.Database.LogLevel,
+		Production:      cfg.App.Release(),
+	}
+}
+
+// newRateLimiter tạo Limiter với các policy từ cấu hình. Trả về limiter nil khi rate limit bị tắt.
+// Store được trả về riêng để đăng ký worker dọn dẹp, health check và đóng kết nối
+func newRateLimiter(rateLimitConfig config.RateLimitConfig, cacheConfig config.CacheConfig) (*ratelimit.Limiter, ratelimit.Store, error) {
+	if !rateLimitConfig.Enabled {
+		return nil, nil, nil
+	}
+
+	var policies []ratelimit.Policy
+	for name, spec := range rateLimitConfig.Policies() {
+		policy, err := ratelimit.ParsePolicy(name, spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	var store ratelimit.Store
+	switch rateLimitConfig.Store {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "redis":
+		redisStore, err := ratelimit.NewRedisStore(cacheConfig.RedisAddr, cacheConfig.RedisPassword,
+			cacheConfig.RedisDB, rateLimitConfig.Prefix)
+		if err != nil {
+			return nil, nil, err
+		}
+		store = redisStore
+	default:
+		return nil, nil, fmt.Errorf("unsupported rate limit store: %q", rateLimitConfig.Store)
+	}
+
+	return ratelimit.New(store, policies...), store, nil
+}
+
+// setupLogging cấu hình level, sink stdout, sink file có rotate và sampling cho DefaultLogger
+func setupLogging(logConfig config.LogConfig) error {
+	level, err := logging.ParseLevel(logConfig.Level)
+	if err != nil {
+		return err
+	}
+	stdoutLevel, err := logging.ParseLevel(logConfig.StdoutLevel)
+	if err != nil {
+		return err
+	}
+
+	sinks := []logging.Sink{{Name: "stdout", Writer: os.Stdout, MinLevel: stdoutLevel}}
+	if logConfig.File != "" {
+		fileLevel, err := logging.ParseLevel(logConfig.FileLevel)
+		if err != nil {
+			return err
+		}
+		file, err := logging.NewRotatingFile(logging.RotateConfig{
+			Path:       logConfig.File,
+			MaxSize:    int64(logConfig.FileMaxSizeMB) * 1024 * 1024,
+			Interval:   logConfig.FileRotateInterval,
+			MaxBackups: logConfig.FileMaxBackups,
+			Compress:   logConfig.FileCompress,
+		})
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, logging.Sink{Name: "file", Writer: file, MinLevel: fileLevel})
+	}
+
+	redactor, err := newRedactor(logConfig)
+	if err != nil {
+		return err
+	}
+
+	logging.DefaultLogger.SetLevel(level)
+	logging.DefaultLogger.SetSinks(sinks)
+	logging.DefaultLogger.SetRedactor(redactor)
+	logging.DefaultLogger.SetDebugSampling(logConfig.DebugSampleFirst, logConfig.DebugSampleThereafter, time.Second)
+	return nil
+}
+
+// newRedactor tạo Redactor từ danh sách "key:mode" trong cấu hình
+func newRedactor(logConfig config.LogConfig) (*logging.Redactor, error) {
+	keys, err := logging.ParseRedactKeys(logConfig.RedactKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	patternMode, err := logging.ParseRedactMode(logConfig.RedactPatternMode)
+	if err != nil {
+		return nil, err
+	}
+	patterns := []logging.RedactPattern{
+		{Name: "jwt", Regexp: logging.JWTPattern, Mode: patternMode},
+		{Name: "bearer", Regexp: logging.BearerPattern, Mode: patternMode},
+	}
+
+	return logging.NewRedactor(keys, patterns, []byte(logConfig.RedactHashKey)), nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/yourusername/tastygo/internal/database"
+)
+
+const migrateUsage = `Usage: tastygo migrate <command> [arguments] [flags]
+
+Commands:
+  up              Apply all pending migrations
+  down [steps]    Roll back the last applied migration(s), default 1
+  status          Show applied and pending migrations
+  create <name>   Create a new pair of up/down migration files
+
+Flags for up, down and status are configuration flags, e.g. --config file or --db-dsn.
+
+Flags for create:
+  -dir string     Directory of migration files (default "migrations")
+`
+
+// runMigrate xử lý subcommand `migrate` và trả về exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	command, args := args[0], args[1:]
+
+	// create chỉ làm việc với file, không cần kết nối database
+	if command == "create" {
+		flags := flag.NewFlagSet("create", flag.ContinueOnError)
+		dir := flags.String("dir", "migrations", "directory of migration files")
+		if err := flags.Parse(args); err != nil {
+			return 2
+		}
+		if flags.NArg() != 1 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+
+		// Mỗi driver có bộ migration riêng, tạo file cho tất cả để giữ cùng phiên bản
+		for _, driver := range database.Drivers {
+			upPath, downPath, err := database.CreateMigration(filepath.Join(*dir, driver), flags.Arg(0))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "create migration: %v\n", err)
+				return 1
+			}
+			fmt.Printf("Created %s\nCreated %s\n", upPath, downPath)
+		}
+		return 0
+	}
+
+	// Tham số vị trí (số bước của down) đứng trước, các flag cấu hình phía sau
+	positional, flags := splitPositional(args)
+	if positional != "" && (command == "up" || command == "status") {
+		fmt.Fprintf(os.Stderr, "unexpected argument: %s\n\n%s", positional, migrateUsage)
+		return 2
+	}
+	cfg, err := loadToolConfig(flags, "database", "app.gin_mode")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load config: %v\n", err)
+		return 1
+	}
+	db, err := database.Open(databaseConfig(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open database: %v\n", err)
+		return 1
+	}
+
+	migrationFS, err := database.MigrationFS(cfg.Database.Driver)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load migrations: %v\n", err)
+		return 1
+	}
+
+	migrator, err := database.NewMigrator(db, migrationFS)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load migrations: %v\n", err)
+		return 1
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("Applied %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate up: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+
+	case "down":
+		steps := 1
+		if positional != "" {
+			steps, err = strconv.Atoi(positional)
+			if err != nil || steps < 1 {
+				fmt.Fprintf(os.Stderr, "invalid steps: %q\n", positional)
+				return 2
+			}
+		}
+
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("Rolled back %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate down: %v\n", err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No applied migrations")
+		}
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate status: %v\n", err)
+			return 1
+		}
+
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Modified {
+				state += " (modified)"
+			}
+			if status.Missing {
+				state += " (missing file)"
+			}
+			fmt.Printf("%03d_%-40s %s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command: %s\n\n%s", command, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -1,34 +1,84 @@
 package config
 
 import (
-    "os"
-    "strconv"
+    "net"
+    "strings"
+    "time"
 )
 
 // AppConfig chứa cấu hình ứng dụng
 type AppConfig struct {
-    Port      int
-    JWTSecret string
-    GinMode   string
-    LogLevel  string
+    Port      int    `config:"port" env:"PORT"`
+    JWTSecret string `config:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+    GinMode   string `config:"gin_mode" env:"GIN_MODE"`
+
+    // Timeout của HTTP server
+    ReadTimeout  time.Duration `config:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+    WriteTimeout time.Duration `config:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+    IdleTimeout  time.Duration `config:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+
+    // TrustedProxies là danh sách IP hoặc CIDR của reverse proxy được tin cậy, phân cách bằng
+    // dấu phẩy. Chỉ request từ các địa chỉ này mới được lấy IP client từ X-Forwarded-For;
+    // để trống là không tin proxy nào và luôn dùng địa chỉ kết nối
+    TrustedProxies string `config:"trusted_proxies" env:"TRUSTED_PROXIES"`
+
+    // ShutdownDelay là thời gian chờ sau khi báo chưa sẵn sàng để load balancer
+    // ngừng gửi traffic, trước khi bắt đầu drain các request đang xử lý
+    ShutdownDelay time.Duration `config:"shutdown_delay" env:"SHUTDOWN_DELAY"`
+    // ShutdownTimeout là thời gian tối đa để drain request và chạy các shutdown hook
+    ShutdownTimeout time.Duration `config:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
 }
 
-// LoadAppConfig tải cấu hình từ biến môi trường
-func LoadAppConfig() AppConfig {
-    port, _ := strconv.Atoi(getEnvOrDefault("PORT", "8080"))
-    
+// Release cho biết ứng dụng chạy ở chế độ production
+func (c AppConfig) Release() bool {
+    return c.GinMode == "release"
+}
+
+// TrustedProxyList trả về danh sách IP/CIDR của reverse proxy được tin cậy
+func (c AppConfig) TrustedProxyList() []string {
+    return splitList(c.TrustedProxies)
+}
+
+// defaultAppConfig trả về cấu hình ứng dụng mặc định
+func defaultAppConfig() AppConfig {
     return AppConfig{
-        Port:      port,
-        JWTSecret: getEnvOrDefault("JWT_SECRET", ""),
-        GinMode:   getEnvOrDefault("GIN_MODE", "debug"),
-        LogLevel:  getEnvOrDefault("LOG_LEVEL", "INFO"),
+        Port:    8080,
+        GinMode: "debug",
+
+        ReadTimeout:     15 * time.Second,
+        WriteTimeout:    30 * time.Second,
+        IdleTimeout:     120 * time.Second,
+        ShutdownDelay:   5 * time.Second,
+        ShutdownTimeout: 30 * time.Second,
     }
 }
 
-// getEnvOrDefault lấy giá trị từ biến môi trường hoặc giá trị mặc định
-func getEnvOrDefault(key, defaultValue string) string {
-    if value := os.Getenv(key); value != "" {
-        return value
+// validate kiểm tra cấu hình ứng dụng
+func (c AppConfig) validate(v *validator) {
+    v.check(c.Port > 0 && c.Port <= 65535, "app.port: must be between 1 and 65535, got %d", c.Port)
+    v.oneOf("app.gin_mode", c.GinMode, "debug", "release", "test")
+
+    // JWT secret để trống thì server tự sinh secret ngẫu nhiên, chỉ chấp nhận khi phát triển
+    switch {
+    case c.JWTSecret == "" && c.Release():
+        v.add("app.jwt_secret: required in release mode (set JWT_SECRET or JWT_SECRET_FILE)")
+    case c.JWTSecret == "change_this_in_production":
+        v.add("app.jwt_secret: the placeholder value from the Dockerfile must be replaced")
+    case c.JWTSecret != "" && len(c.JWTSecret) < 32:
+        v.add("app.jwt_secret: must be at least 32 characters long, got %d", len(c.JWTSecret))
+    }
+
+    v.positive("app.read_timeout", c.ReadTimeout)
+    v.positive("app.write_timeout", c.WriteTimeout)
+    v.positive("app.idle_timeout", c.IdleTimeout)
+    for _, proxy := range c.TrustedProxyList() {
+        if strings.Contains(proxy, "/") {
+            _, _, err := net.ParseCIDR(proxy)
+            v.check(err == nil, "app.trusted_proxies: %q is not a valid CIDR", proxy)
+        } else {
+            v.check(net.ParseIP(proxy) != nil, "app.trusted_proxies: %q is not a valid IP address", proxy)
+        }
     }
-    return defaultValue
+    v.check(c.ShutdownDelay >= 0, "app.shutdown_delay: must not be negative")
+    v.positive("app.shutdown_timeout", c.ShutdownTimeout)
 }
@@ -0,0 +1,30 @@
+package config
+
+// CacheConfig chứa cấu hình cache
+type CacheConfig struct {
+    // Backend là "memory" (mặc định, chỉ dùng trong một instance) hoặc "redis"
+    Backend    string `config:"backend" env:"CACHE_BACKEND"`
+    MaxEntries int    `config:"max_entries" env:"CACHE_MAX_ENTRIES"`
+
+    RedisAddr     string `config:"redis_addr" env:"REDIS_ADDR"`
+    RedisPassword string `config:"redis_password" env:"REDIS_PASSWORD" secret:"true"`
+    RedisDB       int    `config:"redis_db" env:"REDIS_DB"`
+    Prefix        string `config:"prefix" env:"CACHE_PREFIX"`
+}
+
+// defaultCacheConfig trả về cấu hình cache mặc định
+func defaultCacheConfig() CacheConfig {
+    return CacheConfig{
+        Backend:    "memory",
+        MaxEntries: 10000,
+        RedisAddr:  "localhost:6379",
+        Prefix:     "tastygo:",
+    }
+}
+
+// validate kiểm tra cấu hình cache
+func (c CacheConfig) validate(v *validator) {
+    v.oneOf("cache.backend", c.Backend, "memory", "redis")
+    v.check(c.MaxEntries >= 0, "cache.max_entries: must not be negative")
+    v.check(c.RedisDB >= 0, "cache.redis_db: must not be negative")
+}
@@ -0,0 +1,442 @@
+package config
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/pelletier/go-toml/v2"
+    "gopkg.in/yaml.v3"
+)
+
+// RedactedValue thay cho giá trị bí mật khi in cấu hình
+const RedactedValue = "[REDACTED]"
+
+// Config là toàn bộ cấu hình của ứng dụng. Giá trị được nạp theo thứ tự ưu tiên tăng dần:
+// mặc định, file cấu hình (YAML hoặc TOML), biến môi trường rồi tới flag dòng lệnh.
+//
+// Mỗi trường có key trong file (tag config, theo section), biến môi trường (tag env) và
+// flag tương ứng là tên biến môi trường viết thường với "-" thay cho "_" (PORT là --port,
+// DB_DRIVER là --db-driver). Mọi biến môi trường X đều đọc được từ file qua X_FILE,
+// dùng cho secret được mount vào container
+type Config struct {
+    App       AppConfig       `config:"app"`
+    Database  DBConfig        `config:"database"`
+    Mail      MailConfig      `config:"mail"`
+    Log       LogConfig       `config:"log"`
+    Cache     CacheConfig     `config:"cache"`
+    RateLimit RateLimitConfig `config:"rate_limit"`
+    CORS      CORSConfig      `config:"cors"`
+    Session   SessionConfig   `config:"session"`
+    Security  SecurityConfig  `config:"security"`
+    TLS       TLSConfig       `config:"tls"`
+    Metrics   MetricsConfig   `config:"metrics"`
+}
+
+// Default trả về cấu hình mặc định
+func Default() *Config {
+    return &Config{
+        App:       defaultAppConfig(),
+        Database:  defaultDBConfig(),
+        Mail:      defaultMailConfig(),
+        Log:       defaultLogConfig(),
+        Cache:     defaultCacheConfig(),
+        RateLimit: defaultRateLimitConfig(),
+        CORS:      defaultCORSConfig(),
+        Session:   defaultSessionConfig(),
+        Security:  defaultSecurityConfig(),
+        TLS:       defaultTLSConfig(),
+        Metrics:   defaultMetricsConfig(),
+    }
+}
+
+// ValidationError liệt kê mọi lỗi cấu hình thay vì dừng ở lỗi đầu tiên
+type ValidationError struct {
+    Errors []string
+}
+
+func (e *ValidationError) Error() string {
+    return "invalid configuration:\n  - " + strings.Join(e.Errors, "\n  - ")
+}
+
+// Filter chỉ giữ các lỗi liên quan tới keys, mỗi key là một section ("database") hoặc key
+// đầy đủ ("app.port"). Lỗi báo theo biến môi trường hay flag được quy về key tương ứng, lỗi
+// không xác định được key luôn được giữ. Trả về nil khi không còn lỗi nào
+func (e *ValidationError) Filter(keys ...string) *ValidationError {
+    byName := make(map[string]string)
+    for _, f := range Default().fields() {
+        byName[f.env] = f.key()
+        byName[f.env+"_FILE"] = f.key()
+        byName["--"+f.flagName()] = f.key()
+    }
+
+    var kept []string
+    for _, message := range e.Errors {
+        name, _, _ := strings.Cut(message, ":")
+        if key, ok := byName[name]; ok {
+            name = key
+        }
+        section, _, qualified := strings.Cut(name, ".")
+        if !qualified && !isSection(name) {
+            kept = append(kept, message)
+            continue
+        }
+        for _, key := range keys {
+            keySection, _, _ := strings.Cut(key, ".")
+            // Lỗi chung của một section (như "tls: ...") liên quan tới mọi key trong section đó
+            if key == name || key == section || !qualified && name == keySection {
+                kept = append(kept, message)
+                break
+            }
+        }
+    }
+    if len(kept) == 0 {
+        return nil
+    }
+    return &ValidationError{Errors: kept}
+}
+
+// isSection cho biết name là tên một section cấu hình
+func isSection(name string) bool {
+    root := reflect.TypeOf(Config{})
+    for i := 0; i < root.NumField(); i++ {
+        if root.Field(i).Tag.Get("config") == name {
+            return true
+        }
+    }
+    return false
+}
+
+// Load nạp cấu hình từ file, biến môi trường và flag trong args. File cấu hình được chỉ định
+// qua flag --config hoặc biến CONFIG_FILE. Khi cấu hình không hợp lệ, Config vẫn được trả về
+// cùng *ValidationError để có thể in ra kiểm tra
+func Load(args []string) (*Config, error) {
+    cfg := Default()
+    fields := cfg.fields()
+
+    flags := flag.NewFlagSet("tastygo", flag.ContinueOnError)
+    flags.SetOutput(io.Discard)
+    configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML configuration file")
+
+    // Flag được áp dụng sau file và biến môi trường nên chỉ ghi lại ở bước parse
+    type flagValue struct {
+        field *field
+        value string
+    }
+    var flagValues []flagValue
+    for i := range fields {
+        f := &fields[i]
+        flags.Func(f.flagName(), "overrides "+f.env, func(value string) error {
+            flagValues = append(flagValues, flagValue{field: f, value: value})
+            return nil
+        })
+    }
+    if err := flags.Parse(args); err != nil {
+        return nil, err
+    }
+    if flags.NArg() > 0 {
+        return nil, fmt.Errorf("unexpected argument: %s", flags.Arg(0))
+    }
+
+    var v validator
+    if *configFile != "" {
+        if err := cfg.loadFile(*configFile, fields, &v); err != nil {
+            return nil, err
+        }
+    }
+    for i := range fields {
+        fields[i].loadEnv(&v)
+    }
+    for _, fv := range flagValues {
+        if err := fv.field.set(fv.value); err != nil {
+            v.add("--%s: %v", fv.field.flagName(), err)
+        }
+    }
+
+    cfg.normalize()
+    cfg.validate(&v)
+    if len(v.errors) > 0 {
+        return cfg, &ValidationError{Errors: v.errors}
+    }
+    return cfg, nil
+}
+
+// normalize điền các giá trị phụ thuộc vào trường khác
+func (c *Config) normalize() {
+    if c.Database.DSN == "" && c.Database.Driver == "sqlite" {
+        c.Database.DSN = c.Database.Path
+    }
+}
+
+// Validate kiểm tra toàn bộ cấu hình, trả về *ValidationError liệt kê mọi lỗi
+func (c *Config) Validate() error {
+    var v validator
+    c.validate(&v)
+    if len(v.errors) > 0 {
+        return &ValidationError{Errors: v.errors}
+    }
+    return nil
+}
+
+func (c *Config) validate(v *validator) {
+    c.App.validate(v)
+    c.Database.validate(v)
+    c.Mail.validate(v)
+    c.Log.validate(v)
+    c.Cache.validate(v)
+    c.RateLimit.validate(v)
+    c.CORS.validate(v)
+    c.Session.validate(v)
+    c.Security.validate(v)
+    c.TLS.validate(v)
+    c.Metrics.validate(v)
+
+    // Cookie session không Secure sẽ gửi token qua HTTP thường, chỉ chấp nhận khi phát triển
+    if c.Session.CookieEnabled && c.App.Release() && !c.Session.CookieSecure {
+        v.add("session.cookie_secure: required in release mode when cookie sessions are enabled")
+    }
+    if c.TLS.RedirectPort != 0 {
+        v.check(c.TLS.RedirectPort != c.App.Port, "tls.redirect_port: must differ from app.port")
+    }
+    if c.Metrics.Addr != "" {
+        v.check(c.Metrics.Port() != c.App.Port, "metrics.addr: port must differ from app.port")
+    }
+}
+
+// loadFile đọc file YAML (.yaml, .yml) hoặc TOML (.toml). Key không xác định được báo lỗi
+// để phát hiện lỗi chính tả
+func (c *Config) loadFile(path string, fields []field, v *validator) error {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return fmt.Errorf("read config file: %w", err)
+    }
+
+    var sections map[string]interface{}
+    switch ext := strings.ToLower(filepath.Ext(path)); ext {
+    case ".yaml", ".yml":
+        err = yaml.Unmarshal(data, &sections)
+    case ".toml":
+        err = toml.Unmarshal(data, &sections)
+    default:
+        return fmt.Errorf("unsupported config file extension %q, use .yaml, .yml or .toml", ext)
+    }
+    if err != nil {
+        return fmt.Errorf("parse config file %s: %w", path, err)
+    }
+
+    byKey := make(map[string]*field, len(fields))
+    for i := range fields {
+        byKey[fields[i].key()] = &fields[i]
+    }
+
+    for _, section := range sortedKeys(sections) {
+        values, ok := sections[section].(map[string]interface{})
+        if !ok {
+            v.add("%s: expected a section", section)
+            continue
+        }
+        for _, name := range sortedKeys(values) {
+            key, value := section+"."+name, values[name]
+            f, ok := byKey[key]
+            if !ok {
+                v.add("%s: unknown key", key)
+                continue
+            }
+            if err := f.set(scalarString(value)); err != nil {
+                v.add("%s: %v", key, err)
+            }
+        }
+    }
+    return nil
+}
+
+// sortedKeys trả về các key của m theo thứ tự để lỗi được báo ổn định
+func sortedKeys(m map[string]interface{}) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// scalarString chuyển giá trị đọc từ file về dạng chuỗi như biến môi trường
+func scalarString(value interface{}) string {
+    switch value := value.(type) {
+    case string:
+        return value
+    case time.Duration:
+        return value.String()
+    case nil:
+        return ""
+    }
+    return fmt.Sprint(value)
+}
+
+// field là một trường cấu hình có thể đặt từ file, biến môi trường hoặc flag
+type field struct {
+    section string
+    name    string
+    env     string
+    secret  bool
+    value   reflect.Value
+}
+
+// fields liệt kê các trường của c theo thứ tự khai báo
+func (c *Config) fields() []field {
+    var fields []field
+    root := reflect.ValueOf(c).Elem()
+    for i := 0; i < root.NumField(); i++ {
+        section := root.Type().Field(i).Tag.Get("config")
+        group := root.Field(i)
+        for j := 0; j < group.NumField(); j++ {
+            tag := group.Type().Field(j)
+            fields = append(fields, field{
+                section: section,
+                name:    tag.Tag.Get("config"),
+                env:     tag.Tag.Get("env"),
+                secret:  tag.Tag.Get("secret") == "true",
+                value:   group.Field(j),
+            })
+        }
+    }
+    return fields
+}
+
+func (f *field) key() string {
+    return f.section + "." + f.name
+}
+
+func (f *field) flagName() string {
+    return strings.ReplaceAll(strings.ToLower(f.env), "_", "-")
+}
+
+// loadEnv đọc giá trị từ biến môi trường hoặc từ file chỉ định qua <ENV>_FILE.
+// Biến rỗng được bỏ qua như chưa đặt
+func (f *field) loadEnv(v *validator) {
+    value := os.Getenv(f.env)
+    if path := os.Getenv(f.env + "_FILE"); path != "" {
+        if value != "" {
+            v.add("%s: both %s and %s_FILE are set", f.env, f.env, f.env)
+            return
+        }
+        data, err := os.ReadFile(path)
+        if err != nil {
+            v.add("%s_FILE: %v", f.env, err)
+            return
+        }
+        value = strings.TrimRight(string(data), "\r\n")
+    }
+    if value == "" {
+        return
+    }
+    if err := f.set(value); err != nil {
+        v.add("%s: %v", f.env, err)
+    }
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set gán giá trị dạng chuỗi cho trường theo kiểu của trường
+func (f *field) set(raw string) error {
+    raw = strings.TrimSpace(raw)
+    switch {
+    case f.value.Type() == durationType:
+        d, err := time.ParseDuration(raw)
+        if err != nil {
+            return fmt.Errorf("invalid duration %q", raw)
+        }
+        f.value.SetInt(int64(d))
+    case f.value.Kind() == reflect.Int:
+        n, err := strconv.Atoi(raw)
+        if err != nil {
+            return fmt.Errorf("invalid integer %q", raw)
+        }
+        f.value.SetInt(int64(n))
+    case f.value.Kind() == reflect.Bool:
+        b, err := strconv.ParseBool(raw)
+        if err != nil {
+            return fmt.Errorf("invalid boolean %q", raw)
+        }
+        f.value.SetBool(b)
+    case f.value.Kind() == reflect.String:
+        f.value.SetString(raw)
+    default:
+        return errors.New("unsupported field type " + f.value.Type().String())
+    }
+    return nil
+}
+
+// String trả về giá trị hiện tại dạng chuỗi
+func (f *field) String() string {
+    if f.value.Type() == durationType {
+        return time.Duration(f.value.Int()).String()
+    }
+    return fmt.Sprint(f.value.Interface())
+}
+
+// Write ghi cấu hình dạng YAML (cùng định dạng với file cấu hình). Khi redacted, giá trị
+// của các trường bí mật (JWT secret, mật khẩu, DSN...) được thay bằng RedactedValue
+func (c *Config) Write(w io.Writer, redacted bool) error {
+    root := &yaml.Node{Kind: yaml.MappingNode}
+    var section *yaml.Node
+    for _, f := range c.fields() {
+        if section == nil || root.Content[len(root.Content)-2].Value != f.section {
+            section = &yaml.Node{Kind: yaml.MappingNode}
+            root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.section}, section)
+        }
+
+        value := &yaml.Node{Kind: yaml.ScalarNode, Value: f.String()}
+        if f.value.Kind() == reflect.String && f.value.Type() != durationType {
+            value.Tag = "!!str"
+        }
+        if redacted && f.secret && f.String() != "" {
+            value.Value = RedactedValue
+        }
+        section.Content = append(section.Content,
+            &yaml.Node{Kind: yaml.ScalarNode, Value: f.name, LineComment: f.env}, value)
+    }
+
+    encoder := yaml.NewEncoder(w)
+    encoder.SetIndent(2)
+    if err := encoder.Encode(root); err != nil {
+        return err
+    }
+    return encoder.Close()
+}
+
+// validator gom các lỗi cấu hình
+type validator struct {
+    errors []string
+}
+
+func (v *validator) add(format string, args ...interface{}) {
+    v.errors = append(v.errors, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) check(ok bool, format string, args ...interface{}) {
+    if !ok {
+        v.add(format, args...)
+    }
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+    for _, a := range allowed {
+        if value == a {
+            return
+        }
+    }
+    v.add("%s: must be one of %s, got %q", key, strings.Join(allowed, ", "), value)
+}
+
+func (v *validator) positive(key string, d time.Duration) {
+    v.check(d > 0, "%s: must be positive, got %s", key, d)
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/yourusername/tastygo/internal/cors"
+)
+
+// CORSConfig chứa cấu hình CORS. Các danh sách phân tách bằng dấu phẩy
+type CORSConfig struct {
+    // AllowedOrigins là origin được gọi API từ trình duyệt, hỗ trợ wildcard như
+    // "https://*.tastygo.vn"; để trống thì không cho origin nào
+    AllowedOrigins   string        `config:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+    AllowedMethods   string        `config:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+    AllowedHeaders   string        `config:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+    ExposedHeaders   string        `config:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+    AllowCredentials bool          `config:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+    MaxAge           time.Duration `config:"max_age" env:"CORS_MAX_AGE"`
+
+    // Routes ghi đè danh sách origin theo prefix path, dạng "prefix=origin,origin;prefix=origin".
+    // Route cho mọi origin ("*") luôn tắt credentials, các thiết lập khác giữ như mặc định
+    Routes string `config:"routes" env:"CORS_ROUTES"`
+}
+
+// defaultCORSConfig trả về cấu hình CORS mặc định, cho phép dashboard chạy local
+func defaultCORSConfig() CORSConfig {
+    return CORSConfig{
+        AllowedOrigins:   "http://localhost:3000",
+        AllowedMethods:   "GET, POST, PUT, PATCH, DELETE",
+        AllowedHeaders:   "Content-Type, Authorization, Accept, Cache-Control, X-Requested-With, X-Request-ID, X-CSRF-Token",
+        ExposedHeaders:   "X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After",
+        AllowCredentials: true,
+        MaxAge:           10 * time.Minute,
+    }
+}
+
+// Policy trả về cấu hình cho middleware CORS
+func (c CORSConfig) Policy() (cors.Config, error) {
+    policy := cors.Policy{
+        AllowedOrigins:   splitList(c.AllowedOrigins),
+        AllowedMethods:   splitList(c.AllowedMethods),
+        AllowedHeaders:   splitList(c.AllowedHeaders),
+        ExposedHeaders:   splitList(c.ExposedHeaders),
+        AllowCredentials: c.AllowCredentials,
+        MaxAge:           c.MaxAge,
+    }
+    cfg := cors.Config{Default: policy}
+
+    for _, entry := range strings.Split(c.Routes, ";") {
+        if strings.TrimSpace(entry) == "" {
+            continue
+        }
+        prefix, origins, ok := strings.Cut(entry, "=")
+        prefix = strings.TrimSpace(prefix)
+        if !ok || !strings.HasPrefix(prefix, "/") {
+            return cors.Config{}, fmt.Errorf("invalid route %q, expected /prefix=origin[,origin]", strings.TrimSpace(entry))
+        }
+
+        route := policy
+        route.AllowedOrigins = splitList(origins)
+        for _, origin := range route.AllowedOrigins {
+            if origin == cors.AnyOrigin {
+                route.AllowCredentials = false
+            }
+        }
+        cfg.Routes = append(cfg.Routes, cors.Route{Prefix: prefix, Policy: route})
+    }
+    return cfg, nil
+}
+
+// validate kiểm tra cấu hình CORS
+func (c CORSConfig) validate(v *validator) {
+    cfg, err := c.Policy()
+    if err != nil {
+        v.add("cors.routes: %v", err)
+        return
+    }
+    validateOrigins(v, "cors.allowed_origins", cfg.Default)
+    for _, route := range cfg.Routes {
+        validateOrigins(v, "cors.routes: "+route.Prefix, route.Policy)
+    }
+    v.check(len(cfg.Default.AllowedMethods) > 0, "cors.allowed_methods: must not be empty")
+    v.check(c.MaxAge >= 0, "cors.max_age: must not be negative")
+}
+
+// validateOrigins kiểm tra từng origin của policy. Trình duyệt không chấp nhận "*" kèm
+// credentials, cho phép mọi origin kèm cookie cũng là lỗ hổng nên báo lỗi thay vì tự sửa
+func validateOrigins(v *validator, key string, policy cors.Policy) {
+    for _, origin := range policy.AllowedOrigins {
+        if err := cors.CheckOrigin(origin); err != nil {
+            v.add("%s: %v", key, err)
+        }
+        if origin == cors.AnyOrigin && policy.AllowCredentials {
+            v.add("%s: \"*\" cannot be combined with allow_credentials", key)
+        }
+    }
+}
+
+// splitList tách danh sách phân tách bằng dấu phẩy, bỏ phần tử rỗng
+func splitList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
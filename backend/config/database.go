@@ -1,13 +1,45 @@
 package config
 
+import (
+    "time"
+)
+
 // DBConfig chứa cấu hình database
 type DBConfig struct {
-    Path string
+    // Driver là "sqlite" (mặc định, dùng cho phát triển) hoặc "postgres"
+    Driver string `config:"driver" env:"DB_DRIVER"`
+    // DSN là connection string; với SQLite mặc định là Path
+    DSN  string `config:"dsn" env:"DB_DSN" secret:"true"`
+    Path string `config:"path" env:"DB_PATH"`
+
+    // Cấu hình connection pool
+    MaxOpenConns    int           `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+    MaxIdleConns    int           `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+    ConnMaxLifetime time.Duration `config:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+    ConnMaxIdleTime time.Duration `config:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+
+    // LogLevel là mức log của GORM; câu SQL được ghi qua logger chung, không kèm giá trị bind
+    LogLevel string `config:"log_level" env:"DB_LOG_LEVEL"`
 }
 
-// LoadDBConfig tải cấu hình database từ biến môi trường
-func LoadDBConfig() DBConfig {
+// defaultDBConfig trả về cấu hình database mặc định
+func defaultDBConfig() DBConfig {
     return DBConfig{
-        Path: getEnvOrDefault("DB_PATH", "tastygo.db"),
+        Driver:          "sqlite",
+        Path:            "tastygo.db",
+        MaxOpenConns:    25,
+        MaxIdleConns:    5,
+        ConnMaxLifetime: 30 * time.Minute,
+        ConnMaxIdleTime: 5 * time.Minute,
+        LogLevel:        "warn",
     }
 }
+
+// validate kiểm tra cấu hình database
+func (c DBConfig) validate(v *validator) {
+    v.oneOf("database.driver", c.Driver, "sqlite", "postgres")
+    v.oneOf("database.log_level", c.LogLevel, "silent", "error", "warn", "info")
+    v.check(c.DSN != "", "database.dsn: required")
+    v.check(c.MaxOpenConns >= 0, "database.max_open_conns: must not be negative")
+    v.check(c.MaxIdleConns >= 0, "database.max_idle_conns: must not be negative")
+}
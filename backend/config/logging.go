@@ -0,0 +1,76 @@
+package config
+
+import (
+    "time"
+
+    "github.com/yourusername/tastygo/internal/logging"
+)
+
+// LogConfig chứa cấu hình các đích ghi log
+type LogConfig struct {
+    // Level là cấp độ tối thiểu chung, có thể đổi lúc chạy qua API
+    Level string `config:"level" env:"LOG_LEVEL"`
+    // StdoutLevel là cấp độ tối thiểu riêng của stdout
+    StdoutLevel string `config:"stdout_level" env:"LOG_STDOUT_LEVEL"`
+
+    // File là đường dẫn file log; để trống thì chỉ ghi ra stdout
+    File               string        `config:"file" env:"LOG_FILE"`
+    FileLevel          string        `config:"file_level" env:"LOG_FILE_LEVEL"`
+    FileMaxSizeMB      int           `config:"file_max_size_mb" env:"LOG_FILE_MAX_SIZE_MB"`
+    FileRotateInterval time.Duration `config:"file_rotate_interval" env:"LOG_FILE_ROTATE_INTERVAL"`
+    FileMaxBackups     int           `config:"file_max_backups" env:"LOG_FILE_MAX_BACKUPS"`
+    FileCompress       bool          `config:"file_compress" env:"LOG_FILE_COMPRESS"`
+
+    // Sampling cho log DEBUG: mỗi giây, mỗi message chỉ ghi DebugSampleFirst lần đầu,
+    // sau đó cứ DebugSampleThereafter lần ghi một lần. DebugSampleFirst = 0 là tắt
+    DebugSampleFirst      int `config:"debug_sample_first" env:"LOG_DEBUG_SAMPLE_FIRST"`
+    DebugSampleThereafter int `config:"debug_sample_thereafter" env:"LOG_DEBUG_SAMPLE_THEREAFTER"`
+
+    // RedactKeys là danh sách "key:mode" các trường nhạy cảm, mode là drop, mask hoặc hash
+    RedactKeys string `config:"redact_keys" env:"LOG_REDACT_KEYS"`
+    // RedactPatternMode là mode áp dụng cho JWT và bearer token tìm thấy trong chuỗi
+    RedactPatternMode string `config:"redact_pattern_mode" env:"LOG_REDACT_PATTERN_MODE"`
+    // RedactHashKey là khóa HMAC cho mode hash; nên đặt giống nhau giữa các replica
+    RedactHashKey string `config:"redact_hash_key" env:"LOG_REDACT_HASH_KEY" secret:"true"`
+}
+
+// defaultLogConfig trả về cấu hình log mặc định
+func defaultLogConfig() LogConfig {
+    return LogConfig{
+        Level:                 "INFO",
+        StdoutLevel:           "DEBUG",
+        FileLevel:             "DEBUG",
+        FileMaxSizeMB:         100,
+        FileRotateInterval:    24 * time.Hour,
+        FileMaxBackups:        7,
+        FileCompress:          true,
+        DebugSampleThereafter: 100,
+        RedactKeys:            "password:drop,token:mask,authorization:mask,email:hash,ip:hash",
+        RedactPatternMode:     "mask",
+    }
+}
+
+// validate kiểm tra cấu hình log
+func (c LogConfig) validate(v *validator) {
+    levels := []struct{ key, level string }{
+        {"log.level", c.Level},
+        {"log.stdout_level", c.StdoutLevel},
+        {"log.file_level", c.FileLevel},
+    }
+    for _, l := range levels {
+        if _, err := logging.ParseLevel(l.level); err != nil {
+            v.add("%s: %v", l.key, err)
+        }
+    }
+    v.check(c.FileMaxSizeMB >= 0, "log.file_max_size_mb: must not be negative")
+    v.check(c.FileMaxBackups >= 0, "log.file_max_backups: must not be negative")
+    v.check(c.DebugSampleFirst >= 0, "log.debug_sample_first: must not be negative")
+    v.check(c.DebugSampleThereafter > 0, "log.debug_sample_thereafter: must be positive")
+
+    if _, err := logging.ParseRedactKeys(c.RedactKeys); err != nil {
+        v.add("log.redact_keys: %v", err)
+    }
+    if _, err := logging.ParseRedactMode(c.RedactPatternMode); err != nil {
+        v.add("log.redact_pattern_mode: %v", err)
+    }
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+    "net/url"
+)
+
+// MailConfig chứa cấu hình gửi email
+type MailConfig struct {
+    SMTPHost     string `config:"smtp_host" env:"SMTP_HOST"`
+    SMTPPort     int    `config:"smtp_port" env:"SMTP_PORT"`
+    SMTPUsername string `config:"smtp_username" env:"SMTP_USERNAME"`
+    SMTPPassword string `config:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+    From         string `config:"from" env:"MAIL_FROM"`
+    Dir          string `config:"dir" env:"MAIL_DIR"`
+    FrontendURL  string `config:"frontend_url" env:"FRONTEND_URL"`
+}
+
+// defaultMailConfig trả về cấu hình email mặc định
+func defaultMailConfig() MailConfig {
+    return MailConfig{
+        SMTPPort:    587,
+        From:        "no-reply@tastygo.com",
+        Dir:         "mail",
+        FrontendURL: "http://localhost:3000",
+    }
+}
+
+// validate kiểm tra cấu hình email
+func (c MailConfig) validate(v *validator) {
+    if c.SMTPHost != "" {
+        v.check(c.SMTPPort > 0 && c.SMTPPort <= 65535, "mail.smtp_port: must be between 1 and 65535, got %d", c.SMTPPort)
+    }
+    v.check(c.From != "", "mail.from: required")
+    if u, err := url.Parse(c.FrontendURL); err != nil || u.Scheme == "" || u.Host == "" {
+        v.add("mail.frontend_url: must be an absolute URL, got %q", c.FrontendURL)
+    }
+}
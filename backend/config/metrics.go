@@ -0,0 +1,43 @@
+package config
+
+import (
+    "net"
+    "strconv"
+)
+
+// MetricsConfig chứa cấu hình endpoint Prometheus. /metrics không nằm trên listener công khai
+// mà chạy trên listener nội bộ riêng để không lộ số liệu vận hành ra Internet
+type MetricsConfig struct {
+    // Addr là địa chỉ host:port của listener nội bộ phục vụ /metrics; để trống là tắt
+    Addr string `config:"addr" env:"METRICS_ADDR"`
+}
+
+// Port trả về cổng của listener metrics, 0 khi bị tắt hoặc địa chỉ không hợp lệ
+func (c MetricsConfig) Port() int {
+    _, port, err := net.SplitHostPort(c.Addr)
+    if err != nil {
+        return 0
+    }
+    n, _ := strconv.Atoi(port)
+    return n
+}
+
+// defaultMetricsConfig trả về cấu hình metrics mặc định, chỉ nghe trên loopback
+func defaultMetricsConfig() MetricsConfig {
+    return MetricsConfig{
+        Addr: "127.0.0.1:9090",
+    }
+}
+
+// validate kiểm tra cấu hình metrics
+func (c MetricsConfig) validate(v *validator) {
+    if c.Addr == "" {
+        return
+    }
+    if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+        v.add("metrics.addr: %v", err)
+        return
+    }
+    port := c.Port()
+    v.check(port > 0 && port <= 65535, "metrics.addr: port must be between 1 and 65535, got %q", c.Addr)
+}
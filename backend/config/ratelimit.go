@@ -0,0 +1,66 @@
+package config
+
+import (
+    "github.com/yourusername/tastygo/internal/ratelimit"
+)
+
+// RateLimitConfig chứa cấu hình rate limit
+type RateLimitConfig struct {
+    Enabled bool `config:"enabled" env:"RATE_LIMIT_ENABLED"`
+    // Store là "memory" (mặc định, riêng từng instance) hoặc "redis" (dùng chung giữa các
+    // replica, kết nối theo cấu hình Redis của cache)
+    Store  string `config:"store" env:"RATE_LIMIT_STORE"`
+    Prefix string `config:"prefix" env:"RATE_LIMIT_PREFIX"`
+
+    // Giới hạn của từng nhóm route dạng "limit/window[:key]", key là ip, user hoặc api_key
+    Login string `config:"login" env:"RATE_LIMIT_LOGIN"`
+    Auth  string `config:"auth" env:"RATE_LIMIT_AUTH"`
+    Read  string `config:"read" env:"RATE_LIMIT_READ"`
+    Write string `config:"write" env:"RATE_LIMIT_WRITE"`
+    // API giới hạn theo IP mọi request tới route cần đăng nhập, áp dụng trước khi xác thực
+    // nên chỉ dùng được key ip
+    API string `config:"api" env:"RATE_LIMIT_API"`
+}
+
+// Policies trả về policy của từng nhóm route theo tên
+func (c RateLimitConfig) Policies() map[string]string {
+    return map[string]string{
+        ratelimit.PolicyLogin: c.Login,
+        ratelimit.PolicyAuth:  c.Auth,
+        ratelimit.PolicyRead:  c.Read,
+        ratelimit.PolicyWrite: c.Write,
+        ratelimit.PolicyAPI:   c.API,
+    }
+}
+
+// defaultRateLimitConfig trả về cấu hình rate limit mặc định
+func defaultRateLimitConfig() RateLimitConfig {
+    return RateLimitConfig{
+        Enabled: true,
+        Store:   "memory",
+        Prefix:  "tastygo:ratelimit:",
+        Login:   "10/1m:ip",
+        Auth:    "5/1m:ip",
+        Read:    "300/1m:user",
+        Write:   "60/1m:user",
+        API:     "600/1m:ip",
+    }
+}
+
+// validate kiểm tra cấu hình rate limit
+func (c RateLimitConfig) validate(v *validator) {
+    if !c.Enabled {
+        return
+    }
+    v.oneOf("rate_limit.store", c.Store, "memory", "redis")
+    policies := c.Policies()
+    for _, name := range []string{ratelimit.PolicyLogin, ratelimit.PolicyAuth, ratelimit.PolicyRead, ratelimit.PolicyWrite, ratelimit.PolicyAPI} {
+        policy, err := ratelimit.ParsePolicy(name, policies[name])
+        if err != nil {
+            v.add("rate_limit.%s: invalid policy %q, expected limit/window[:ip|user|api_key]", name, policies[name])
+            continue
+        }
+        v.check(name != ratelimit.PolicyAPI || policy.Key == ratelimit.KeyIP,
+            "rate_limit.api: must be keyed by ip, it applies before authentication")
+    }
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+    "time"
+)
+
+// SecurityConfig chứa cấu hình các header bảo mật gửi kèm mọi response.
+// Để trống một header là không gửi header đó
+type SecurityConfig struct {
+    // HSTSMaxAge chỉ được gửi trên kết nối HTTPS; 0 là tắt HSTS
+    HSTSMaxAge            time.Duration `config:"hsts_max_age" env:"SECURITY_HSTS_MAX_AGE"`
+    HSTSIncludeSubdomains bool          `config:"hsts_include_subdomains" env:"SECURITY_HSTS_INCLUDE_SUBDOMAINS"`
+
+    ContentSecurityPolicy string `config:"content_security_policy" env:"SECURITY_CSP"`
+    FrameOptions          string `config:"frame_options" env:"SECURITY_FRAME_OPTIONS"`
+    ReferrerPolicy        string `config:"referrer_policy" env:"SECURITY_REFERRER_POLICY"`
+}
+
+// defaultSecurityConfig trả về cấu hình mặc định cho API chỉ trả JSON
+func defaultSecurityConfig() SecurityConfig {
+    return SecurityConfig{
+        HSTSMaxAge:            180 * 24 * time.Hour,
+        ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+        FrameOptions:          "DENY",
+        ReferrerPolicy:        "no-referrer",
+    }
+}
+
+// validate kiểm tra cấu hình header bảo mật
+func (c SecurityConfig) validate(v *validator) {
+    v.check(c.HSTSMaxAge >= 0, "security.hsts_max_age: must not be negative")
+    if c.FrameOptions != "" {
+        v.oneOf("security.frame_options", c.FrameOptions, "DENY", "SAMEORIGIN")
+    }
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+    "net/http"
+)
+
+// SessionConfig chứa cấu hình chế độ cookie session cho dashboard. API client vẫn dùng
+// header Authorization như trước
+type SessionConfig struct {
+    // CookieEnabled cho phép đăng nhập với use_cookie để nhận token qua cookie HttpOnly
+    CookieEnabled bool   `config:"cookie_enabled" env:"SESSION_COOKIE_ENABLED"`
+    CookieDomain  string `config:"cookie_domain" env:"SESSION_COOKIE_DOMAIN"`
+    CookieSecure  bool   `config:"cookie_secure" env:"SESSION_COOKIE_SECURE"`
+    // CookieSameSite là "lax", "strict" hoặc "none" (bắt buộc cookie_secure)
+    CookieSameSite string `config:"cookie_samesite" env:"SESSION_COOKIE_SAMESITE"`
+}
+
+// SameSite trả về giá trị SameSite tương ứng cho http.Cookie
+func (c SessionConfig) SameSite() http.SameSite {
+    switch c.CookieSameSite {
+    case "strict":
+        return http.SameSiteStrictMode
+    case "none":
+        return http.SameSiteNoneMode
+    default:
+        return http.SameSiteLaxMode
+    }
+}
+
+// defaultSessionConfig trả về cấu hình session mặc định
+func defaultSessionConfig() SessionConfig {
+    return SessionConfig{
+        CookieSecure:   true,
+        CookieSameSite: "lax",
+    }
+}
+
+// validate kiểm tra cấu hình session
+func (c SessionConfig) validate(v *validator) {
+    if !c.CookieEnabled {
+        return
+    }
+    v.oneOf("session.cookie_samesite", c.CookieSameSite, "lax", "strict", "none")
+    if c.CookieSameSite == "none" {
+        v.check(c.CookieSecure, "session.cookie_samesite: \"none\" requires cookie_secure")
+    }
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+    "crypto/tls"
+    "os"
+    "strings"
+    "time"
+)
+
+// TLSConfig chứa cấu hình HTTPS. TLS được bật khi đặt cả CertFile và KeyFile
+type TLSConfig struct {
+    CertFile string `config:"cert_file" env:"TLS_CERT_FILE"`
+    KeyFile  string `config:"key_file" env:"TLS_KEY_FILE"`
+    // MinVersion là "1.2" hoặc "1.3"
+    MinVersion string `config:"min_version" env:"TLS_MIN_VERSION"`
+    // ReloadInterval là chu kỳ kiểm tra file chứng chỉ để nạp lại khi thay đổi
+    ReloadInterval time.Duration `config:"reload_interval" env:"TLS_RELOAD_INTERVAL"`
+
+    // ClientCAFile bật mutual TLS: các route trong ClientCertRoutes chỉ nhận request có
+    // chứng chỉ client được CA này ký
+    ClientCAFile     string `config:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+    ClientCertRoutes string `config:"client_cert_routes" env:"TLS_CLIENT_CERT_ROUTES"`
+
+    // RedirectPort là cổng HTTP chuyển hướng mọi request sang HTTPS; 0 là tắt
+    RedirectPort int `config:"redirect_port" env:"TLS_REDIRECT_PORT"`
+}
+
+// Enabled cho biết server phục vụ HTTPS
+func (c TLSConfig) Enabled() bool {
+    return c.CertFile != "" && c.KeyFile != ""
+}
+
+// TLSVersion trả về phiên bản TLS tối thiểu cho tls.Config
+func (c TLSConfig) TLSVersion() uint16 {
+    if c.MinVersion == "1.3" {
+        return tls.VersionTLS13
+    }
+    return tls.VersionTLS12
+}
+
+// ClientCertPrefixes trả về các prefix path yêu cầu chứng chỉ client
+func (c TLSConfig) ClientCertPrefixes() []string {
+    return splitList(c.ClientCertRoutes)
+}
+
+// defaultTLSConfig trả về cấu hình TLS mặc định (tắt)
+func defaultTLSConfig() TLSConfig {
+    return TLSConfig{
+        MinVersion:     "1.2",
+        ReloadInterval: 30 * time.Second,
+    }
+}
+
+// validate kiểm tra cấu hình TLS, file chứng chỉ phải đọc được ngay lúc khởi động
+func (c TLSConfig) validate(v *validator) {
+    v.check((c.CertFile == "") == (c.KeyFile == ""), "tls: cert_file and key_file must be set together")
+    files := []struct{ key, path string }{
+        {"tls.cert_file", c.CertFile},
+        {"tls.key_file", c.KeyFile},
+        {"tls.client_ca_file", c.ClientCAFile},
+    }
+    for _, f := range files {
+        if f.path == "" {
+            continue
+        }
+        if _, err := os.Stat(f.path); err != nil {
+            v.add("%s: %v", f.key, err)
+        }
+    }
+    if !c.Enabled() {
+        v.check(c.ClientCAFile == "", "tls.client_ca_file: requires cert_file and key_file")
+        v.check(c.RedirectPort == 0, "tls.redirect_port: requires cert_file and key_file")
+        return
+    }
+    v.oneOf("tls.min_version", c.MinVersion, "1.2", "1.3")
+    v.positive("tls.reload_interval", c.ReloadInterval)
+    v.check(c.ClientCAFile == "" || len(c.ClientCertPrefixes()) > 0,
+        "tls.client_cert_routes: required when client_ca_file is set")
+    for _, prefix := range c.ClientCertPrefixes() {
+        v.check(strings.HasPrefix(prefix, "/"), "tls.client_cert_routes: %q must start with /", prefix)
+    }
+    v.check(c.RedirectPort >= 0 && c.RedirectPort <= 65535, "tls.redirect_port: must be between 0 and 65535, got %d", c.RedirectPort)
+}
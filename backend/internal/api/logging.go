@@ -0,0 +1,117 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/tastygo/internal/logging"
+)
+
+// RequestIDHeader là header mang correlation ID giữa các service
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware gán request ID cho mỗi request (dùng lại X-Request-ID nếu client gửi lên
+// hợp lệ), trả về trong response header và gắn RequestInfo vào context để logging.FromContext dùng
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		info := &logging.RequestInfo{
+			ID:     id,
+			Method: c.Request.Method,
+			Route:  c.FullPath(),
+			Start:  time.Now(),
+		}
+		c.Request = c.Request.WithContext(logging.WithRequest(c.Request.Context(), info))
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+// AccessLogMiddleware ghi một bản ghi JSON cho mỗi request, thay cho logger dạng text của gin
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		data := map[string]interface{}{
+			"path":       c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"bytes":      c.Writer.Size(),
+			"client_ip":  c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		}
+		if len(c.Errors) > 0 {
+			data["errors"] = c.Errors.String()
+		}
+
+		logger := logging.FromContext(c.Request.Context())
+		switch status := c.Writer.Status(); {
+		case status >= http.StatusInternalServerError:
+			logger.Error("HTTP request", data)
+		case status >= http.StatusBadRequest:
+			logger.Warn("HTTP request", data)
+		default:
+			logger.Info("HTTP request", data)
+		}
+	}
+}
+
+// validRequestID chỉ chấp nhận ID ngắn gồm ký tự in được, tránh chèn dữ liệu lạ vào log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID tạo request ID ngẫu nhiên 128 bit
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// HandleGetLogLevel trả về cấp độ log hiện tại
+func HandleGetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": logging.DefaultLogger.Level().String()})
+}
+
+// HandleSetLogLevel đổi cấp độ log lúc đang chạy, không cần khởi động lại server
+func HandleSetLogLevel(c *gin.Context) {
+	var req struct {
+		Level string `json:"level" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous := logging.DefaultLogger.Level()
+	logging.DefaultLogger.SetLevel(level)
+
+	// Ghi ở mức WARN để thay đổi luôn xuất hiện trong log, trừ khi level mới là ERROR trở lên
+	logging.FromContext(c.Request.Context()).Warn("Log level changed", map[string]interface{}{
+		"from": previous.String(),
+		"to":   level.String(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"level": level.String()})
+}
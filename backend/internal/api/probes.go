@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/tastygo/internal/buildinfo"
+	"github.com/yourusername/tastygo/internal/health"
+)
+
+// RegisterProbes gắn các endpoint cho container orchestrator và load balancer:
+//   - /healthz: process còn sống, không kiểm tra phụ thuộc
+//   - /readyz: chạy mọi Checker đã đăng ký, trả về 503 nếu có kiểm tra thất bại
+//   - /version: thông tin build
+func RegisterProbes(router *gin.Engine, checks *health.Registry) {
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+	})
+
+	router.GET("/readyz", func(c *gin.Context) {
+		report := checks.Run(c.Request.Context())
+		if !report.Healthy() {
+			c.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	})
+
+	router.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, buildinfo.Get())
+	})
+}
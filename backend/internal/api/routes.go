@@ -4,40 +4,85 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/yourusername/tastygo/internal/auth"
 	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/ratelimit"
 )
 
-func SetupRoutes(router *gin.Engine) {
+// SetupRoutes đăng ký các route; limiter nil là không giới hạn request
+func SetupRoutes(router *gin.Engine, h *auth.Handler, limiter *ratelimit.Limiter) {
     // Public routes
-    router.POST("/api/auth/login", auth.HandleLogin)
+    login := limiter.Policy(ratelimit.PolicyLogin)
+    public := limiter.Policy(ratelimit.PolicyAuth)
+    router.POST("/api/auth/login", login, h.HandleLogin)
+    router.POST("/api/auth/refresh", limiter.Policy(ratelimit.PolicyWrite), h.HandleRefresh)
+    router.POST("/api/auth/mfa/verify", login, h.HandleVerifyMFA)
+    router.POST("/api/auth/forgot-password", public, h.HandleForgotPassword)
+    router.POST("/api/auth/reset-password", public, h.HandleResetPasswordWithToken)
+    router.POST("/api/auth/register", public, h.HandleRegister)
+    router.POST("/api/auth/verify-email", public, h.HandleVerifyEmail)
+    router.POST("/api/auth/resend-verification", public, h.HandleResendVerification)
     
-    // Protected routes
+    // Protected routes: giới hạn theo IP trước khi xác thực để chặn dò token/API key,
+    // rồi theo user hoặc API key sau khi xác thực
     authRoutes := router.Group("/api")
-    authRoutes.Use(auth.AuthMiddleware())
+    authRoutes.Use(limiter.Policy(ratelimit.PolicyAPI), h.AuthMiddleware(), limiter.ByMethod(ratelimit.PolicyRead, ratelimit.PolicyWrite))
     {
-        authRoutes.POST("/auth/logout", auth.HandleLogout)
-        authRoutes.GET("/profile", auth.HandleGetProfile)
+        authRoutes.GET("/profile", h.HandleGetProfile)
         
-        // Admin routes
-        adminRoutes := authRoutes.Group("/admin")
-        adminRoutes.Use(auth.RoleMiddleware(models.RoleAdmin, models.RoleSuperAdmin))
+        // Quản lý tài khoản của chính user: không chấp nhận API key
+        account := authRoutes.Group("", auth.SessionOnly())
         {
-            // Routes accessible by both admin and superadmin
-            adminRoutes.GET("/dashboard", func(c *gin.Context) {
-                c.JSON(200, gin.H{"message": "Admin dashboard"})
-            })
+            account.POST("/auth/logout", h.HandleLogout)
+            account.POST("/auth/mfa/enroll", h.HandleEnrollMFA)
+            account.POST("/auth/mfa/confirm", h.HandleConfirmMFA)
+            account.POST("/auth/mfa/disable", h.HandleDisableMFA)
+            account.PATCH("/profile", h.HandleUpdateProfile)
+            account.POST("/profile/password", h.HandleChangePassword)
+            account.GET("/sessions", h.HandleListSessions)
+            account.DELETE("/sessions", h.HandleRevokeOtherSessions)
+            account.DELETE("/sessions/:id", h.HandleRevokeSession)
         }
         
-        // SuperAdmin routes
-        superAdminRoutes := authRoutes.Group("/admin")
-        superAdminRoutes.Use(auth.RoleMiddleware(models.RoleSuperAdmin))
+        // Admin routes: mỗi route yêu cầu quyền cụ thể thay vì role cố định
+        adminRoutes := authRoutes.Group("/admin")
         {
-            // Routes accessible only by superadmin
-            superAdminRoutes.POST("/users", auth.HandleCreateAdmin)
-            superAdminRoutes.GET("/users/admins", auth.HandleListAdmins)
-            superAdminRoutes.POST("/users/reset-password", auth.HandleResetPassword)
-            superAdminRoutes.POST("/users/update-status", auth.HandleUpdateUserStatus)
-            superAdminRoutes.POST("/users/unlock-account", auth.HandleUnlockAccount) // Thêm route mới
-            superAdminRoutes.GET("/logs", auth.HandleGetActivityLogs)
+            adminRoutes.GET("/dashboard", h.RequirePermission(models.PermDashboardView), func(c *gin.Context) {
+                c.JSON(200, gin.H{"message": "Admin dashboard"})
+            })
+            
+            adminRoutes.GET("/users", h.RequirePermission(models.PermUsersRead), h.HandleListUsers)
+            adminRoutes.POST("/users", h.RequirePermission(models.PermUsersCreate), h.HandleCreateAdmin)
+            adminRoutes.GET("/users/:id", h.RequirePermission(models.PermUsersRead), h.HandleGetUser)
+            adminRoutes.PATCH("/users/:id", h.RequirePermission(models.PermUsersUpdate), h.HandleUpdateUser)
+            adminRoutes.DELETE("/users/:id", h.RequirePermission(models.PermUsersDelete), h.HandleDeleteUser)
+            adminRoutes.POST("/users/:id/restore", h.RequirePermission(models.PermUsersDelete), h.HandleRestoreUser)
+            adminRoutes.GET("/users/admins", h.RequirePermission(models.PermUsersRead), h.HandleListAdmins)
+            adminRoutes.POST("/users/reset-password", h.RequirePermission(models.PermUsersResetPassword), h.HandleResetPassword)
+            adminRoutes.POST("/users/update-status", h.RequirePermission(models.PermUsersUpdateStatus), h.HandleUpdateUserStatus)
+            adminRoutes.POST("/users/unlock-account", h.RequirePermission(models.PermUsersUnlock), h.HandleUnlockAccount)
+            adminRoutes.POST("/users/revoke-sessions", h.RequirePermission(models.PermUsersRevokeSessions), h.HandleRevokeUserSessions)
+            adminRoutes.POST("/users/reset-mfa", h.RequirePermission(models.PermUsersResetMFA), h.HandleResetMFA)
+            adminRoutes.GET("/mfa-policies", h.RequirePermission(models.PermMFAPolicyManage), h.HandleListMFAPolicies)
+            adminRoutes.PUT("/mfa-policies", h.RequirePermission(models.PermMFAPolicyManage), h.HandleUpdateMFAPolicy)
+            adminRoutes.GET("/logs", h.RequirePermission(models.PermLogsRead), h.HandleGetActivityLogs)
+            
+            // Quản lý role và quyền
+            adminRoutes.GET("/permissions", h.RequirePermission(models.PermRolesManage), h.HandleListPermissions)
+            adminRoutes.GET("/roles", h.RequirePermission(models.PermRolesManage), h.HandleListRoles)
+            adminRoutes.POST("/roles", h.RequirePermission(models.PermRolesManage), h.HandleCreateRole)
+            adminRoutes.GET("/roles/:name", h.RequirePermission(models.PermRolesManage), h.HandleGetRole)
+            adminRoutes.PUT("/roles/:name", h.RequirePermission(models.PermRolesManage), h.HandleUpdateRole)
+            adminRoutes.DELETE("/roles/:name", h.RequirePermission(models.PermRolesManage), h.HandleDeleteRole)
+            
+            // Đổi cấp độ log lúc chạy
+            adminRoutes.GET("/log-level", h.RequirePermission(models.PermLogLevelManage), HandleGetLogLevel)
+            adminRoutes.PUT("/log-level", h.RequirePermission(models.PermLogLevelManage), HandleSetLogLevel)
+            
+            // Quản lý API key cho partner và job nội bộ. Chỉ nhận phiên đăng nhập để một API key
+            // không thể tự tạo key mới
+            apiKeys := adminRoutes.Group("/api-keys", auth.SessionOnly(), h.RequirePermission(models.PermAPIKeysManage))
+            apiKeys.GET("", h.HandleListAPIKeys)
+            apiKeys.POST("", h.HandleCreateAPIKey)
+            apiKeys.DELETE("/:id", h.HandleRevokeAPIKey)
         }
     }
 }
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHeaders là các header bảo mật gửi kèm mọi response. Trường rỗng là không gửi header đó
+type SecurityHeaders struct {
+	// HSTSMaxAge chỉ áp dụng cho request qua HTTPS (trực tiếp hoặc qua proxy với
+	// X-Forwarded-Proto: https); 0 là tắt HSTS
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	ContentSecurityPolicy string
+	FrameOptions          string
+	ReferrerPolicy        string
+}
+
+// SecurityHeadersMiddleware đặt các header bảo mật trước khi handler xử lý request, nên
+// cả response lỗi và preflight CORS cũng có đủ header
+func SecurityHeadersMiddleware(headers SecurityHeaders) gin.HandlerFunc {
+	hsts := ""
+	if headers.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(headers.HSTSMaxAge.Seconds()))
+		if headers.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		if headers.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", headers.ContentSecurityPolicy)
+		}
+		if headers.FrameOptions != "" {
+			header.Set("X-Frame-Options", headers.FrameOptions)
+		}
+		if headers.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", headers.ReferrerPolicy)
+		}
+		if hsts != "" && (c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https") {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
@@ -2,27 +2,121 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/yourusername/tastygo/internal/auth"
+	"github.com/yourusername/tastygo/internal/cors"
+	"github.com/yourusername/tastygo/internal/logging"
+	"github.com/yourusername/tastygo/internal/metrics"
+	"github.com/yourusername/tastygo/internal/ratelimit"
 )
 
-func NewServer() *gin.Engine {
-    router := gin.Default()
+// serverOptions chứa các thành phần tùy chọn của router
+type serverOptions struct {
+    limiter *ratelimit.Limiter
+    cors    *cors.Config
+    cookies *auth.CookieConfig
+    headers *SecurityHeaders
 
-    // CORS middleware
-    router.Use(func(c *gin.Context) {
-        c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-        c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-        c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-        c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+    // clientCertRoutes là các prefix path yêu cầu chứng chỉ client
+    clientCertRoutes []string
+    // trustedProxies là IP/CIDR của reverse proxy được tin cậy khi đọc X-Forwarded-For
+    trustedProxies []string
+}
+
+// Option cấu hình thêm cho NewServer
+type Option func(*serverOptions)
+
+// WithRateLimiter áp dụng rate limit cho các nhóm route. Không có option này thì router
+// không giới hạn request (dùng trong test)
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+    return func(o *serverOptions) {
+        o.limiter = limiter
+    }
+}
+
+// WithCORS cho phép gọi API từ trình duyệt theo cấu hình CORS. Không có option này thì
+// router không gửi header CORS nào, tức chỉ cho phép request cùng origin
+func WithCORS(cfg cors.Config) Option {
+    return func(o *serverOptions) {
+        o.cors = &cfg
+    }
+}
 
-        if c.Request.Method == "OPTIONS" {
-            c.AbortWithStatus(204)
-            return
-        }
+// WithSessionCookies cho phép dashboard đăng nhập ở chế độ cookie session (use_cookie),
+// các request dùng cookie phải kèm CSRF token
+func WithSessionCookies(cfg auth.CookieConfig) Option {
+    return func(o *serverOptions) {
+        o.cookies = &cfg
+    }
+}
 
-        c.Next()
-    })
+// WithSecurityHeaders gửi các header bảo mật (HSTS, CSP...) kèm mọi response
+func WithSecurityHeaders(headers SecurityHeaders) Option {
+    return func(o *serverOptions) {
+        o.headers = &headers
+    }
+}
+
+// WithClientCertRoutes yêu cầu chứng chỉ client (mutual TLS) cho các path bắt đầu bằng prefixes.
+// Server phải chạy HTTPS với CA của client, nếu không mọi request tới các route này bị từ chối
+func WithClientCertRoutes(prefixes ...string) Option {
+    return func(o *serverOptions) {
+        o.clientCertRoutes = prefixes
+    }
+}
+
+// WithTrustedProxies tin cậy header X-Forwarded-For từ các reverse proxy có IP/CIDR trong proxies.
+// Không có option này thì IP client luôn là địa chỉ kết nối, header do client gửi bị bỏ qua
+func WithTrustedProxies(proxies ...string) Option {
+    return func(o *serverOptions) {
+        o.trustedProxies = proxies
+    }
+}
+
+// NewServer tạo router với các handler được gắn vào auth.Service đã khởi tạo
+func NewServer(authService *auth.Service, opts ...Option) *gin.Engine {
+    var options serverOptions
+    for _, opt := range opts {
+        opt(&options)
+    }
+
+    // Dùng access log JSON thay cho logger text mặc định của gin
+    router := gin.New()
+    // Mặc định gin tin mọi proxy, khi đó client tự đặt X-Forwarded-For để đổi IP, né rate limit
+    // và làm sai IP trong log. Danh sách proxy đã được kiểm tra khi nạp cấu hình
+    if err := router.SetTrustedProxies(options.trustedProxies); err != nil {
+        logging.Error("Invalid trusted proxies, ignoring forwarded headers", map[string]interface{}{
+            "error": err.Error(),
+        })
+        router.SetTrustedProxies(nil)
+    }
+    router.Use(gin.Recovery(), RequestIDMiddleware(), AccessLogMiddleware(), metrics.Middleware())
+
+    if options.headers != nil {
+        router.Use(SecurityHeadersMiddleware(*options.headers))
+    }
+    if len(options.clientCertRoutes) > 0 {
+        router.Use(ClientCertMiddleware(options.clientCertRoutes))
+    }
+    if options.cors != nil {
+        router.Use(cors.Middleware(*options.cors))
+    }
+
+    handler := auth.NewHandler(authService)
+    if options.cookies != nil {
+        handler.EnableCookieSessions(*options.cookies)
+    }
+    SetupRoutes(router, handler, options.limiter)
+
+    return router
+}
 
-    SetupRoutes(router)
+// NewMetricsServer tạo router chỉ phục vụ /metrics cho listener nội bộ, tách khỏi router công khai
+// để metric không bị truy cập từ Internet
+func NewMetricsServer(authService *auth.Service) *gin.Engine {
+    metrics.SetActiveSessionsSource(authService.CountActiveSessions)
 
+    router := gin.New()
+    router.Use(gin.Recovery())
+    router.GET("/metrics", gin.WrapH(metrics.Handler()))
     return router
 }
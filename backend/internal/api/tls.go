@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientCertMiddleware chỉ cho request có chứng chỉ client đã được xác minh (mutual TLS) truy cập
+// các path bắt đầu bằng một trong prefixes. Dùng cho route nội bộ như /api/admin/log-level
+func ClientCertMiddleware(prefixes []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		for _, prefix := range prefixes {
+			prefix = strings.TrimSuffix(prefix, "/")
+			if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+				continue
+			}
+			if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "client certificate required"})
+				return
+			}
+			break
+		}
+		c.Next()
+	}
+}
+
+// HTTPSRedirectHandler chuyển hướng mọi request HTTP sang cùng URL trên HTTPS ở cổng httpsPort.
+// Dùng 308 để client giữ nguyên method và body
+func HTTPSRedirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
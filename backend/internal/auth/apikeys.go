@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/repository"
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("API key not found")
+	ErrInvalidAPIKey       = errors.New("invalid or expired API key")
+	ErrAPIKeyPermissions   = errors.New("API key requires at least one permission")
+	ErrPermissionNotInRole = errors.New("permission is not granted to the owner's role")
+	ErrInvalidAPIKeyExpiry = errors.New("expiry must be in the future")
+	ErrUserSessionRequired = errors.New("this action requires a user session, API keys are not accepted")
+)
+
+const (
+	// apiKeyScheme là tiền tố cố định của mọi API key, giúp nhận diện key bị lộ trong log hay mã nguồn
+	apiKeyScheme = "tg"
+	// apiKeyUsageInterval là khoảng tối thiểu giữa hai lần ghi nhận sử dụng key từ cùng một IP,
+	// tránh mỗi request đều ghi database và activity log
+	apiKeyUsageInterval = time.Minute
+)
+
+// CreateAPIKeyInput là thông tin để tạo API key cho user sở hữu
+type CreateAPIKeyInput struct {
+	Name        string
+	UserID      uint
+	Permissions []models.Permission
+	// ExpiresAt nil là key không hết hạn
+	ExpiresAt *time.Time
+}
+
+// CreateAPIKey tạo API key cho user và trả về key dạng plaintext, chỉ hiển thị một lần.
+// Quyền của key phải nằm trong quyền của role user sở hữu
+func (s *Service) CreateAPIKey(actorID uint, input CreateAPIKeyInput) (*models.APIKey, string, error) {
+	if len(input.Permissions) == 0 {
+		return nil, "", ErrAPIKeyPermissions
+	}
+	if err := validatePermissions(input.Permissions); err != nil {
+		return nil, "", err
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidAPIKeyExpiry
+	}
+
+	owner, err := s.GetUser(input.UserID, false)
+	if err != nil {
+		return nil, "", err
+	}
+	if !owner.Active {
+		return nil, "", ErrAccountDisabled
+	}
+	for _, permission := range input.Permissions {
+		if !s.HasPermission(owner.Role, permission) {
+			return nil, "", ErrPermissionNotInRole
+		}
+	}
+
+	prefixBytes := make([]byte, 6)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return nil, "", err
+	}
+	prefix := hex.EncodeToString(prefixBytes)
+	secret, secretHash, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := models.APIKey{
+		Name:        input.Name,
+		Prefix:      prefix,
+		SecretHash:  secretHash,
+		UserID:      owner.ID,
+		CreatedBy:   actorID,
+		Permissions: buildAPIKeyPermissions(input.Permissions),
+		ExpiresAt:   input.ExpiresAt,
+	}
+	if err := s.repos.APIKeys.Create(&key); err != nil {
+		return nil, "", err
+	}
+	return &key, apiKeyScheme + "_" + prefix + "_" + secret, nil
+}
+
+// ListAPIKeys liệt kê API key theo bộ lọc, không bao giờ trả về secret
+func (s *Service) ListAPIKeys(filter repository.APIKeyFilter) ([]models.APIKey, error) {
+	return s.repos.APIKeys.List(filter)
+}
+
+// RevokeAPIKey thu hồi API key, các request sau dùng key bị từ chối ngay
+func (s *Service) RevokeAPIKey(id uint) (*models.APIKey, error) {
+	key, err := s.repos.APIKeys.FindByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	revoked, err := s.repos.APIKeys.Revoke(id, now)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, ErrAPIKeyNotFound
+	}
+	key.RevokedAt = &now
+	return key, nil
+}
+
+// AuthenticateAPIKey kiểm tra API key dạng plaintext và trả về key cùng user sở hữu.
+// Key bị thu hồi, hết hạn, hoặc user sở hữu bị khóa/xóa đều không dùng được
+func (s *Service) AuthenticateAPIKey(rawKey string) (*models.APIKey, *models.User, error) {
+	rest, ok := strings.CutPrefix(rawKey, apiKeyScheme+"_")
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" || secret == "" {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repos.APIKeys.FindByPrefix(prefix)
+	if err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if !key.IsActive(time.Now()) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	owner, err := s.GetUser(key.UserID, false)
+	if err != nil || !owner.Active {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	return key, owner, nil
+}
+
+// RecordAPIKeyUsage cập nhật thời điểm và IP sử dụng gần nhất của key và ghi activity log
+// cho user sở hữu, tối đa một lần mỗi apiKeyUsageInterval với cùng IP
+func (s *Service) RecordAPIKeyUsage(key *models.APIKey, method, path, ipAddress, userAgent string) {
+	now := time.Now()
+	updated, err := s.repos.APIKeys.TouchLastUsed(key.ID, ipAddress, now, now.Add(-apiKeyUsageInterval))
+	if err != nil || !updated {
+		return
+	}
+	s.LogActivity(key.UserID, models.ActivityAPIKeyUsed,
+		fmt.Sprintf("API key %s (%s) used for %s %s", key.Name, key.Prefix, method, path),
+		ipAddress, userAgent)
+}
+
+func buildAPIKeyPermissions(permissions []models.Permission) []models.APIKeyPermission {
+	seen := make(map[models.Permission]bool, len(permissions))
+	rows := make([]models.APIKeyPermission, 0, len(permissions))
+	for _, permission := range permissions {
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+		rows = append(rows, models.APIKeyPermission{Permission: permission})
+	}
+	return rows
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/cache"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+// Thời gian cache profile và tập quyền của role. Sau TTL giá trị cũ vẫn được trả về
+// trong staleTTL trong khi tải lại ở nền; thay đổi dữ liệu thì vô hiệu hóa ngay qua tag
+const (
+	profileTTL         = 5 * time.Minute
+	rolePermissionsTTL = 5 * time.Minute
+	cacheStaleTTL      = time.Minute
+	cacheJitter        = 0.1
+)
+
+func profileCacheKey(userID uint) string {
+	return "profile_" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func rolePermissionsCacheKey(role models.Role) string {
+	return "role_permissions_" + string(role)
+}
+
+// userCacheTag là tag của mọi giá trị cache phụ thuộc vào dữ liệu của một user
+func userCacheTag(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+// roleCacheTag là tag của mọi giá trị cache phụ thuộc vào định nghĩa của một role
+func roleCacheTag(role models.Role) string {
+	return "role:" + string(role)
+}
+
+func profileCacheOptions(userID uint) cache.Options {
+	return cache.Options{
+		TTL:      profileTTL,
+		Jitter:   cacheJitter,
+		StaleTTL: cacheStaleTTL,
+		Tags:     []string{userCacheTag(userID)},
+	}
+}
+
+func rolePermissionsCacheOptions(role models.Role) cache.Options {
+	return cache.Options{
+		TTL:      rolePermissionsTTL,
+		Jitter:   cacheJitter,
+		StaleTTL: cacheStaleTTL,
+		Tags:     []string{roleCacheTag(role)},
+	}
+}
+
+// invalidateUserCache vô hiệu hóa mọi giá trị đã cache của user (profile...) để lần đọc
+// sau lấy dữ liệu mới từ database. Phải được gọi sau mọi thay đổi dữ liệu user
+func (s *Service) invalidateUserCache(userID uint) {
+	s.cache.InvalidateTags(userCacheTag(userID))
+}
+
+// invalidateRoleCache vô hiệu hóa tập quyền đã cache của role
+func (s *Service) invalidateRoleCache(role models.Role) {
+	s.cache.InvalidateTags(roleCacheTag(role))
+}
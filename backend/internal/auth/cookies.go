@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tên cookie và header của chế độ cookie session
+const (
+	AccessCookieName  = "tastygo_access"
+	RefreshCookieName = "tastygo_refresh"
+	// CSRFCookieName không HttpOnly để dashboard đọc được và gửi lại qua CSRFHeaderName
+	CSRFCookieName = "tastygo_csrf"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// Path của cookie: access token gửi kèm mọi request API, refresh token chỉ gửi kèm request refresh
+const (
+	accessCookiePath  = "/api"
+	refreshCookiePath = "/api/auth/refresh"
+	csrfCookiePath    = "/"
+)
+
+var (
+	ErrCookieSessionsDisabled = errors.New("cookie sessions are disabled")
+	ErrInvalidCSRFToken       = errors.New("invalid CSRF token")
+)
+
+// CookieConfig là thuộc tính của cookie session
+type CookieConfig struct {
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// CookieSessionResponse thay cho TokenPair khi đăng nhập ở chế độ cookie: token chỉ nằm trong
+// cookie HttpOnly, client nhận CSRF token để gửi kèm các request thay đổi dữ liệu
+type CookieSessionResponse struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	CSRFToken string    `json:"csrf_token"`
+}
+
+// EnableCookieSessions bật chế độ cookie session với thuộc tính cookie cfg
+func (h *Handler) EnableCookieSessions(cfg CookieConfig) {
+	h.cookies = &cfg
+}
+
+// respondTokens trả cặp token trong body, hoặc đặt cookie session khi client yêu cầu useCookie
+func (h *Handler) respondTokens(c *gin.Context, pair *TokenPair, useCookie bool) {
+	if !useCookie {
+		c.JSON(http.StatusOK, pair)
+		return
+	}
+
+	csrfToken, err := h.setSessionCookies(c, pair)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, CookieSessionResponse{ExpiresAt: pair.ExpiresAt, CSRFToken: csrfToken})
+}
+
+// setSessionCookies đặt cookie access token, refresh token và một CSRF token mới
+func (h *Handler) setSessionCookies(c *gin.Context, pair *TokenPair) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	csrfToken := hex.EncodeToString(buf)
+
+	refreshTTL := h.service.config.RefreshTokenTTL
+	h.setCookie(c, AccessCookieName, pair.AccessToken, accessCookiePath, time.Until(pair.ExpiresAt), true)
+	h.setCookie(c, RefreshCookieName, pair.RefreshToken, refreshCookiePath, refreshTTL, true)
+	h.setCookie(c, CSRFCookieName, csrfToken, csrfCookiePath, refreshTTL, false)
+	return csrfToken, nil
+}
+
+// clearSessionCookies xóa các cookie session
+func (h *Handler) clearSessionCookies(c *gin.Context) {
+	h.setCookie(c, AccessCookieName, "", accessCookiePath, -1, true)
+	h.setCookie(c, RefreshCookieName, "", refreshCookiePath, -1, true)
+	h.setCookie(c, CSRFCookieName, "", csrfCookiePath, -1, false)
+}
+
+// setCookie đặt cookie theo CookieConfig; maxAge âm là xóa cookie
+func (h *Handler) setCookie(c *gin.Context, name, value, path string, maxAge time.Duration, httpOnly bool) {
+	seconds := int(maxAge.Seconds())
+	if maxAge < 0 {
+		seconds = -1
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   h.cookies.Domain,
+		MaxAge:   seconds,
+		Secure:   h.cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: h.cookies.SameSite,
+	})
+}
+
+// sessionCookie trả về giá trị cookie session name, rỗng nếu chế độ cookie tắt hoặc không có cookie
+func (h *Handler) sessionCookie(c *gin.Context, name string) string {
+	if h.cookies == nil {
+		return ""
+	}
+	value, err := c.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// validCSRF kiểm tra double-submit: header CSRFHeaderName phải trùng cookie CSRFCookieName.
+// Trang của origin khác gửi được cookie nhưng không đọc được giá trị để đặt vào header
+func (h *Handler) validCSRF(c *gin.Context) bool {
+	cookie := h.sessionCookie(c, CSRFCookieName)
+	header := c.GetHeader(CSRFHeaderName)
+	return cookie != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+// safeMethod cho biết method không thay đổi dữ liệu nên không cần CSRF token
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
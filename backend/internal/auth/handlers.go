@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -8,15 +10,169 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/yourusername/tastygo/internal/cache"
-	"github.com/yourusername/tastygo/internal/database"
+	"github.com/yourusername/tastygo/internal/logging"
 	"github.com/yourusername/tastygo/internal/models"
 	"github.com/yourusername/tastygo/internal/pagination"
+	"github.com/yourusername/tastygo/internal/repository"
 )
 
+// Handler chứa các HTTP handler của module auth, mọi nghiệp vụ được ủy quyền cho Service
+type Handler struct {
+    service *Service
+    // cookies khác nil khi chế độ cookie session được bật
+    cookies *CookieConfig
+}
+
+// NewHandler tạo Handler từ auth.Service
+func NewHandler(service *Service) *Handler {
+    return &Handler{service: service}
+}
+
 type LoginRequest struct {
     Email    string `json:"email" binding:"required,email"`
     Password string `json:"password" binding:"required"`
+    // UseCookie nhận token qua cookie HttpOnly thay vì trong body (dành cho dashboard)
+    UseCookie bool `json:"use_cookie"`
+}
+
+type RefreshRequest struct {
+    RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type VerifyMFARequest struct {
+    ChallengeToken string `json:"challenge_token" binding:"required"`
+    Code           string `json:"code"`
+    RecoveryCode   string `json:"recovery_code"`
+    UseCookie      bool   `json:"use_cookie"`
+}
+
+type MFACodeRequest struct {
+    Code string `json:"code" binding:"required"`
+}
+
+type DisableMFARequest struct {
+    Password string `json:"password" binding:"required"`
+    Code     string `json:"code" binding:"required"`
+}
+
+type ResetMFARequest struct {
+    UserID uint `json:"user_id" binding:"required"`
+}
+
+type UpdateMFAPolicyRequest struct {
+    Role     models.Role `json:"role" binding:"required"`
+    Required bool        `json:"required"`
+}
+
+type RoleRequest struct {
+    Name        models.Role         `json:"name"`
+    Description string              `json:"description"`
+    Permissions []models.Permission `json:"permissions" binding:"required"`
+}
+
+type RoleResponse struct {
+    Name        models.Role         `json:"name"`
+    Description string              `json:"description"`
+    System      bool                `json:"system"`
+    Permissions []models.Permission `json:"permissions"`
+}
+
+type CreateAPIKeyRequest struct {
+    Name        string              `json:"name" binding:"required"`
+    UserID      uint                `json:"user_id" binding:"required"`
+    Permissions []models.Permission `json:"permissions" binding:"required"`
+    ExpiresAt   *time.Time          `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+    ID          uint                `json:"id"`
+    Name        string              `json:"name"`
+    Prefix      string              `json:"prefix"`
+    UserID      uint                `json:"user_id"`
+    CreatedBy   uint                `json:"created_by"`
+    Permissions []models.Permission `json:"permissions"`
+    ExpiresAt   *time.Time          `json:"expires_at"`
+    LastUsedAt  *time.Time          `json:"last_used_at"`
+    LastUsedIP  string              `json:"last_used_ip"`
+    RevokedAt   *time.Time          `json:"revoked_at"`
+    CreatedAt   time.Time           `json:"created_at"`
+}
+
+// CreateAPIKeyResponse chứa key dạng plaintext, chỉ được trả về một lần khi tạo
+type CreateAPIKeyResponse struct {
+    APIKeyResponse
+    Key string `json:"key"`
+}
+
+type ForgotPasswordRequest struct {
+    Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordWithTokenRequest struct {
+    Token    string `json:"token" binding:"required"`
+    Password string `json:"password" binding:"required"`
+}
+
+type RegisterRequest struct {
+    Email    string `json:"email" binding:"required"`
+    Username string `json:"username" binding:"required"`
+    Password string `json:"password" binding:"required"`
+    FullName string `json:"full_name"`
+    Phone    string `json:"phone"`
+}
+
+type VerifyEmailRequest struct {
+    Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+    Email string `json:"email" binding:"required,email"`
+}
+
+type UpdateUserRequest struct {
+    Email    *string      `json:"email"`
+    Username *string      `json:"username"`
+    Role     *models.Role `json:"role"`
+    Active   *bool        `json:"active"`
+    Profile  *struct {
+        FullName *string `json:"full_name"`
+        Phone    *string `json:"phone"`
+        Address  *string `json:"address"`
+    } `json:"profile"`
+}
+
+type ProfileResponse struct {
+    FullName string `json:"full_name"`
+    Phone    string `json:"phone"`
+    Address  string `json:"address"`
+}
+
+type UserDetailResponse struct {
+    ID              uint            `json:"id"`
+    Email           string          `json:"email"`
+    Username        string          `json:"username"`
+    Role            models.Role     `json:"role"`
+    Active          bool            `json:"active"`
+    Locked          bool            `json:"locked"`
+    LockedUntil     *time.Time      `json:"locked_until"`
+    LastLogin       *time.Time      `json:"last_login"`
+    EmailVerifiedAt *time.Time      `json:"email_verified_at"`
+    TOTPEnabled     bool            `json:"totp_enabled"`
+    CreatedAt       time.Time       `json:"created_at"`
+    UpdatedAt       time.Time       `json:"updated_at"`
+    DeletedAt       *time.Time      `json:"deleted_at,omitempty"`
+    Profile         ProfileResponse `json:"profile"`
+}
+
+type UpdateProfileRequest struct {
+    FullName *string `json:"full_name"`
+    Phone    *string `json:"phone"`
+    Address  *string `json:"address"`
+}
+
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password" binding:"required"`
+    NewPassword     string `json:"new_password" binding:"required"`
 }
 
 type UserResponse struct {
@@ -51,14 +207,32 @@ type UnlockAccountRequest struct {
     UserID uint `json:"user_id" binding:"required"`
 }
 
-func HandleLogin(c *gin.Context) {
+type RevokeUserSessionsRequest struct {
+    UserID uint `json:"user_id" binding:"required"`
+}
+
+type SessionResponse struct {
+    ID           string    `json:"id"`
+    IPAddress    string    `json:"ip_address"`
+    UserAgent    string    `json:"user_agent"`
+    LoginAt      time.Time `json:"login_at"`
+    LastActiveAt time.Time `json:"last_active_at"`
+    ExpiresAt    time.Time `json:"expires_at"`
+    Current      bool      `json:"current"`
+}
+
+func (h *Handler) HandleLogin(c *gin.Context) {
     var req LoginRequest
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
+    if req.UseCookie && h.cookies == nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": ErrCookieSessionsDisabled.Error()})
+        return
+    }
     
-    token, err := Login(req.Email, req.Password, c.ClientIP(), c.GetHeader("User-Agent"))
+    pair, err := h.service.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP(), c.GetHeader("User-Agent"))
     if err != nil {
         status := http.StatusUnauthorized
         if err == ErrUserNotFound {
@@ -68,56 +242,92 @@ func HandleLogin(c *gin.Context) {
         return
     }
     
-    c.JSON(http.StatusOK, gin.H{"token": token})
+    // User bật 2FA: chưa có token, client gửi challenge token tới /api/auth/mfa/verify
+    if pair.MFARequired {
+        c.JSON(http.StatusOK, pair)
+        return
+    }
+    h.respondTokens(c, pair.TokenPair, req.UseCookie)
+}
+
+func (h *Handler) HandleRefresh(c *gin.Context) {
+    // Ở chế độ cookie, refresh token nằm trong cookie và request phải kèm CSRF token
+    refreshToken := h.sessionCookie(c, RefreshCookieName)
+    fromCookie := refreshToken != ""
+    if fromCookie {
+        if !h.validCSRF(c) {
+            c.JSON(http.StatusForbidden, gin.H{"error": ErrInvalidCSRFToken.Error()})
+            return
+        }
+    } else {
+        var req RefreshRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        refreshToken = req.RefreshToken
+    }
+    
+    pair, err := h.service.Refresh(c.Request.Context(), refreshToken, c.ClientIP(), c.GetHeader("User-Agent"))
+    if err != nil {
+        if fromCookie {
+            h.clearSessionCookies(c)
+        }
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+    
+    h.respondTokens(c, pair, fromCookie)
 }
 
-func HandleLogout(c *gin.Context) {
-    authHeader := c.GetHeader("Authorization")
-    parts := strings.Split(authHeader, " ")
-    if len(parts) == 2 {
-        tokenString := parts[1]
-        Logout(tokenString)
+func (h *Handler) HandleLogout(c *gin.Context) {
+    // Access token được AuthMiddleware lấy từ header hoặc cookie
+    h.service.Logout(c.GetString("access_token"))
+    if h.cookies != nil {
+        h.clearSessionCookies(c)
     }
     
     c.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
 }
 
-func HandleGetProfile(c *gin.Context) {
+func (h *Handler) HandleGetProfile(c *gin.Context) {
     userID, _ := c.Get("user_id")
+    id := userID.(uint)
     
-    // Tạo cache key
-    cacheKey := "profile_" + strconv.FormatUint(uint64(userID.(uint)), 10)
-    
-    // Kiểm tra cache
-    if cachedProfile, found := cache.Get(cacheKey); found {
-        c.JSON(http.StatusOK, cachedProfile)
-        return
-    }
-    
-    var user models.User
-    result := database.DB.Preload("Profile").First(&user, userID)
-    if result.Error != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+    // Đọc profile qua cache; cache bị vô hiệu hóa theo tag user:<id> khi user thay đổi
+    var response gin.H
+    err := h.service.cache.GetOrLoad(c.Request.Context(), profileCacheKey(id), &response, profileCacheOptions(id),
+        func(ctx context.Context) (interface{}, error) {
+            user, err := h.service.GetUser(id, false)
+            if err != nil {
+                return nil, err
+            }
+            
+            return gin.H{
+                "id":       user.ID,
+                "email":    user.Email,
+                "username": user.Username,
+                "role":     user.Role,
+                "profile": gin.H{
+                    "full_name": user.Profile.FullName,
+                    "phone":     user.Profile.Phone,
+                    "address":   user.Profile.Address,
+                },
+            }, nil
+        })
+    if err != nil {
+        if errors.Is(err, ErrUserNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
     
-    response := gin.H{
-        "id":       user.ID,
-        "email":    user.Email,
-        "username": user.Username,
-        "role":     user.Role,
-        "profile": gin.H{
-            "full_name": user.Profile.FullName,
-        },
-    }
-    
-    // Lưu vào cache trong 5 phút
-    cache.Set(cacheKey, response, 5*time.Minute)
-    
     c.JSON(http.StatusOK, response)
 }
 
-func HandleCreateAdmin(c *gin.Context) {
+func (h *Handler) HandleCreateAdmin(c *gin.Context) {
     var user models.User
     if err := c.ShouldBindJSON(&user); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -132,15 +342,14 @@ func HandleCreateAdmin(c *gin.Context) {
         return
     }
     
-    result := database.DB.Create(&user)
-    if result.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+    if err := h.service.CreateUser(&user); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
     
     // Ghi log tạo admin mới
     creatorID, _ := c.Get("user_id")
-    LogActivity(creatorID.(uint), models.ActivityCreateUser, 
+    h.service.LogActivity(creatorID.(uint), models.ActivityCreateUser, 
         fmt.Sprintf("Created admin user: %s (ID: %d)", user.Username, user.ID),
         c.ClientIP(), c.GetHeader("User-Agent"))
     
@@ -152,23 +361,15 @@ func HandleCreateAdmin(c *gin.Context) {
     })
 }
 
-func HandleResetPassword(c *gin.Context) {
-    // Chỉ SuperAdmin mới có quyền reset password
-    role, _ := c.Get("role")
-    if role != models.RoleSuperAdmin {
-        c.JSON(http.StatusForbidden, gin.H{"error": "only superadmin can reset passwords"})
-        return
-    }
-    
+func (h *Handler) HandleResetPassword(c *gin.Context) {
     var req ResetPasswordRequest
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
     
-    var user models.User
-    result := database.DB.First(&user, req.UserID)
-    if result.Error != nil {
+    user, err := h.service.GetUser(req.UserID, false)
+    if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
         return
     }
@@ -184,34 +385,29 @@ func HandleResetPassword(c *gin.Context) {
         return
     }
     
-    database.DB.Save(&user)
+    if err := h.service.SaveUser(user); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
     
     // Ghi log reset password
     adminID, _ := c.Get("user_id")
-    LogActivity(adminID.(uint), models.ActivityResetPassword, 
+    h.service.LogActivity(adminID.(uint), models.ActivityResetPassword, 
         fmt.Sprintf("Reset password for user ID: %d", req.UserID),
         c.ClientIP(), c.GetHeader("User-Agent"))
     
     c.JSON(http.StatusOK, gin.H{"message": "password reset successfully"})
 }
 
-func HandleUpdateUserStatus(c *gin.Context) {
-    // Chỉ SuperAdmin mới có quyền kích hoạt/vô hiệu hóa
-    role, _ := c.Get("role")
-    if role != models.RoleSuperAdmin {
-        c.JSON(http.StatusForbidden, gin.H{"error": "only superadmin can update user status"})
-        return
-    }
-    
+func (h *Handler) HandleUpdateUserStatus(c *gin.Context) {
     var req UpdateUserStatusRequest
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
     
-    var user models.User
-    result := database.DB.First(&user, req.UserID)
-    if result.Error != nil {
+    user, err := h.service.GetUser(req.UserID, false)
+    if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
         return
     }
@@ -223,53 +419,39 @@ func HandleUpdateUserStatus(c *gin.Context) {
     }
     
     user.Active = req.Active
-    database.DB.Save(&user)
+    if err := h.service.SaveUser(user); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
     
     status := "activated"
     if !req.Active {
         status = "deactivated"
+        
+        // Đăng xuất user khỏi mọi thiết bị khi bị vô hiệu hóa
+        if _, err := h.service.RevokeAllSessions(user.ID); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
     }
     
     // Ghi log cập nhật trạng thái
     adminID, _ := c.Get("user_id")
-    LogActivity(adminID.(uint), models.ActivityUpdateStatus, 
+    h.service.LogActivity(adminID.(uint), models.ActivityUpdateStatus, 
         fmt.Sprintf("User ID %d %s", req.UserID, status),
         c.ClientIP(), c.GetHeader("User-Agent"))
     
     c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("user %s successfully", status)})
 }
 
-func HandleListAdmins(c *gin.Context) {
-    // Chỉ SuperAdmin mới có quyền xem danh sách Admin
-    role, _ := c.Get("role")
-    if role != models.RoleSuperAdmin {
-        c.JSON(http.StatusForbidden, gin.H{"error": "only superadmin can view admin list"})
-        return
-    }
-    
+func (h *Handler) HandleListAdmins(c *gin.Context) {
     // Lấy tham số phân trang
     params := pagination.Extract(c)
     
-    var admins []models.User
-    var total int64
-    
-    // Đếm tổng số admin
-    query := database.DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin)
-    countResult := query.Count(&total)
-    if countResult.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": countResult.Error.Error()})
-        return
-    }
-    
     // Lấy danh sách admin có phân trang
-    result := database.DB.Preload("Profile").
-        Where("role = ?", models.RoleAdmin).
-        Offset((params.Page - 1) * params.PageSize).
-        Limit(params.PageSize).
-        Find(&admins)
-    
-    if result.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+    admins, total, err := h.service.ListUsers(repository.UserFilter{Role: models.RoleAdmin}, params)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
     
@@ -290,42 +472,26 @@ func HandleListAdmins(c *gin.Context) {
     c.JSON(http.StatusOK, pagination.NewResponse(adminResponses, params))
 }
 
-func HandleGetActivityLogs(c *gin.Context) {
-    // Chỉ SuperAdmin mới có quyền xem logs
-    role, _ := c.Get("role")
-    if role != models.RoleSuperAdmin {
-        c.JSON(http.StatusForbidden, gin.H{"error": "only superadmin can view activity logs"})
-        return
-    }
-    
+func (h *Handler) HandleGetActivityLogs(c *gin.Context) {
     // Lấy tham số phân trang
     params := pagination.Extract(c)
     
-    var logs []models.ActivityLog
-    var total int64
-    
     // Hỗ trợ lọc theo user_id
-    userID := c.Query("user_id")
-    query := database.DB.Model(&models.ActivityLog{}).Order("created_at DESC")
-    
-    if userID != "" {
-        query = query.Where("user_id = ?", userID)
-    }
-    
-    // Đếm tổng số logs
-    countResult := query.Count(&total)
-    if countResult.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": countResult.Error.Error()})
-        return
+    var filter repository.ActivityLogFilter
+    if userID := c.Query("user_id"); userID != "" {
+        id, err := strconv.ParseUint(userID, 10, 64)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+            return
+        }
+        filterID := uint(id)
+        filter.UserID = &filterID
     }
     
     // Lấy logs có phân trang
-    result := query.Offset((params.Page - 1) * params.PageSize).
-        Limit(params.PageSize).
-        Find(&logs)
-    
-    if result.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+    logs, total, err := h.service.ListActivityLogs(filter, params)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
     
@@ -335,23 +501,15 @@ func HandleGetActivityLogs(c *gin.Context) {
     c.JSON(http.StatusOK, pagination.NewResponse(logs, params))
 }
 
-func HandleUnlockAccount(c *gin.Context) {
-    // Chỉ SuperAdmin mới có quyền mở khóa tài khoản
-    role, _ := c.Get("role")
-    if role != models.RoleSuperAdmin {
-        c.JSON(http.StatusForbidden, gin.H{"error": "only superadmin can unlock accounts"})
-        return
-    }
-    
+func (h *Handler) HandleUnlockAccount(c *gin.Context) {
     var req UnlockAccountRequest
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
     
-    var user models.User
-    result := database.DB.First(&user, req.UserID)
-    if result.Error != nil {
+    user, err := h.service.GetUser(req.UserID, false)
+    if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
         return
     }
@@ -365,13 +523,838 @@ func HandleUnlockAccount(c *gin.Context) {
     // Mở khóa tài khoản
     user.LockedUntil = nil
     user.FailedLoginCount = 0
-    database.DB.Save(&user)
+    if err := h.service.SaveUser(user); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
     
     // Ghi log mở khóa tài khoản
     adminID, _ := c.Get("user_id")
-    LogActivity(adminID.(uint), models.ActivityUnlockAccount, 
+    h.service.LogActivity(adminID.(uint), models.ActivityUnlockAccount, 
         fmt.Sprintf("Unlocked account for user ID: %d", req.UserID),
         c.ClientIP(), c.GetHeader("User-Agent"))
     
     c.JSON(http.StatusOK, gin.H{"message": "account unlocked successfully"})
 }
+
+func (h *Handler) HandleListSessions(c *gin.Context) {
+    userID, _ := c.Get("user_id")
+    currentSessionID, _ := c.Get("session_id")
+    
+    sessions, err := h.service.ListSessions(userID.(uint))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    
+    responses := make([]SessionResponse, 0, len(sessions))
+    for _, session := range sessions {
+        responses = append(responses, SessionResponse{
+            ID:           session.FamilyID,
+            IPAddress:    session.IPAddress,
+            UserAgent:    session.UserAgent,
+            LoginAt:      session.LoginAt,
+            LastActiveAt: session.CreatedAt,
+            ExpiresAt:    session.RefreshExpiresAt,
+            Current:      session.FamilyID == currentSessionID,
+        })
+    }
+    
+    c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+func (h *Handler) HandleRevokeSession(c *gin.Context) {
+    userID, _ := c.Get("user_id")
+    sessionID := c.Param("id")
+    
+    if err := h.service.RevokeSession(userID.(uint), sessionID); err != nil {
+        status := http.StatusInternalServerError
+        if err == ErrSessionNotFound {
+            status = http.StatusNotFound
+        }
+        c.JSON(status, gin.H{"error": err.Error()})
+        return
+    }
+    
+    h.service.LogActivity(userID.(uint), models.ActivityRevokeSession,
+        fmt.Sprintf("Revoked session %s", sessionID),
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "session revoked successfully"})
+}
+
+func (h *Handler) HandleRevokeOtherSessions(c *gin.Context) {
+    userID, _ := c.Get("user_id")
+    currentSessionID, _ := c.Get("session_id")
+    
+    count, err := h.service.RevokeOtherSessions(userID.(uint), currentSessionID.(string))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    
+    h.service.LogActivity(userID.(uint), models.ActivityRevokeSession, "Logged out from all other sessions",
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "other sessions revoked successfully", "revoked": count})
+}
+
+func (h *Handler) HandleRevokeUserSessions(c *gin.Context) {
+    var req RevokeUserSessionsRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    user, err := h.service.GetUser(req.UserID, false)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+        return
+    }
+    
+    count, err := h.service.RevokeAllSessions(user.ID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log thu hồi phiên
+    adminID, _ := c.Get("user_id")
+    h.service.LogActivity(adminID.(uint), models.ActivityRevokeSession,
+        fmt.Sprintf("Revoked all sessions for user ID: %d", req.UserID),
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "user sessions revoked successfully", "revoked": count})
+}
+
+func (h *Handler) HandleVerifyMFA(c *gin.Context) {
+    var req VerifyMFARequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    if req.Code == "" && req.RecoveryCode == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "code or recovery_code is required"})
+        return
+    }
+    if req.UseCookie && h.cookies == nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": ErrCookieSessionsDisabled.Error()})
+        return
+    }
+    
+    pair, err := h.service.VerifyMFA(c.Request.Context(), req.ChallengeToken, req.Code, req.RecoveryCode, c.ClientIP(), c.GetHeader("User-Agent"))
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+    
+    h.respondTokens(c, pair, req.UseCookie)
+}
+
+func (h *Handler) HandleEnrollMFA(c *gin.Context) {
+    userID, _ := c.Get("user_id")
+    
+    enrollment, err := h.service.EnrollMFA(userID.(uint))
+    if err != nil {
+        status := http.StatusInternalServerError
+        if err == ErrMFAAlreadyEnabled {
+            status = http.StatusConflict
+        }
+        c.JSON(status, gin.H{"error": err.Error()})
+        return
+    }
+    
+    c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *Handler) HandleConfirmMFA(c *gin.Context) {
+    var req MFACodeRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    userID, _ := c.Get("user_id")
+    codes, err := h.service.ConfirmMFA(userID.(uint), req.Code)
+    if err != nil {
+        status := http.StatusInternalServerError
+        switch err {
+        case ErrInvalidMFACode, ErrMFANotEnrolled:
+            status = http.StatusBadRequest
+        case ErrMFAAlreadyEnabled:
+            status = http.StatusConflict
+        }
+        c.JSON(status, gin.H{"error": err.Error()})
+        return
+    }
+    
+    h.service.LogActivity(userID.(uint), models.ActivityEnableMFA, "Enabled two-factor authentication",
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    // Mã khôi phục chỉ được trả về một lần duy nhất
+    c.JSON(http.StatusOK, gin.H{
+        "message":        "two-factor authentication enabled",
+        "recovery_codes": codes,
+    })
+}
+
+func (h *Handler) HandleDisableMFA(c *gin.Context) {
+    var req DisableMFARequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    userID, _ := c.Get("user_id")
+    if err := h.service.DisableMFA(c.Request.Context(), userID.(uint), req.Password, req.Code, c.ClientIP()); err != nil {
+        status := http.StatusInternalServerError
+        switch {
+        case err == ErrInvalidCredentials, err == ErrInvalidMFACode, err == ErrMFANotEnrolled:
+            status = http.StatusBadRequest
+        case err == ErrMFARequiredByPolicy:
+            status = http.StatusForbidden
+        case errors.Is(err, ErrAccountLocked):
+            status = http.StatusTooManyRequests
+        }
+        c.JSON(status, gin.H{"error": err.Error()})
+        return
+    }
+    
+    h.service.LogActivity(userID.(uint), models.ActivityDisableMFA, "Disabled two-factor authentication",
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+func (h *Handler) HandleResetMFA(c *gin.Context) {
+    var req ResetMFARequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    adminID, _ := c.Get("user_id")
+    if err := h.service.ResetMFA(adminID.(uint), req.UserID); err != nil {
+        c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log reset 2FA
+    h.service.LogActivity(adminID.(uint), models.ActivityResetMFA,
+        fmt.Sprintf("Reset two-factor authentication for user ID: %d", req.UserID),
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication reset successfully"})
+}
+
+func (h *Handler) HandleListMFAPolicies(c *gin.Context) {
+    policies, err := h.service.ListMFAPolicies()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    
+    c.JSON(http.StatusOK, gin.H{"data": policies})
+}
+
+func (h *Handler) HandleUpdateMFAPolicy(c *gin.Context) {
+    var req UpdateMFAPolicyRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    if !h.service.RoleExists(req.Role) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role"})
+        return
+    }
+    
+    if err := h.service.SetMFAPolicy(req.Role, req.Required); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log thay đổi chính sách
+    adminID, _ := c.Get("user_id")
+    h.service.LogActivity(adminID.(uint), models.ActivityUpdateMFAPolicy,
+        fmt.Sprintf("Set two-factor requirement for role %s to %t", req.Role, req.Required),
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "mfa policy updated successfully"})
+}
+
+func newRoleResponse(role *models.RoleDefinition) RoleResponse {
+    return RoleResponse{
+        Name:        role.Name,
+        Description: role.Description,
+        System:      role.System,
+        Permissions: role.PermissionNames(),
+    }
+}
+
+// roleErrorStatus chuyển lỗi quản lý role sang HTTP status tương ứng
+func roleErrorStatus(err error) int {
+    switch err {
+    case ErrRoleNotFound:
+        return http.StatusNotFound
+    case ErrRoleExists, ErrRoleInUse:
+        return http.StatusConflict
+    case ErrSystemRole:
+        return http.StatusForbidden
+    case ErrInvalidPermission:
+        return http.StatusBadRequest
+    }
+    return http.StatusInternalServerError
+}
+
+func (h *Handler) HandleListPermissions(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{"data": models.AllPermissions})
+}
+
+func (h *Handler) HandleListRoles(c *gin.Context) {
+    roles, err := h.service.ListRoles()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    
+    responses := make([]RoleResponse, 0, len(roles))
+    for i := range roles {
+        responses = append(responses, newRoleResponse(&roles[i]))
+    }
+    
+    c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+func (h *Handler) HandleGetRole(c *gin.Context) {
+    role, err := h.service.GetRole(models.Role(c.Param("name")))
+    if err != nil {
+        c.JSON(roleErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    c.JSON(http.StatusOK, newRoleResponse(role))
+}
+
+func (h *Handler) HandleCreateRole(c *gin.Context) {
+    var req RoleRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    if req.Name == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+        return
+    }
+    
+    role, err := h.service.CreateRole(req.Name, req.Description, req.Permissions)
+    if err != nil {
+        c.JSON(roleErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log tạo role
+    adminID, _ := c.Get("user_id")
+    h.service.LogActivity(adminID.(uint), models.ActivityManageRole,
+        fmt.Sprintf("Created role %s with permissions %v", role.Name, role.PermissionNames()),
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusCreated, newRoleResponse(role))
+}
+
+func (h *Handler) HandleUpdateRole(c *gin.Context) {
+    var req RoleRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    role, err := h.service.UpdateRole(models.Role(c.Param("name")), req.Description, req.Permissions)
+    if err != nil {
+        c.JSON(roleErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log cập nhật role
+    adminID, _ := c.Get("user_id")
+    h.service.LogActivity(adminID.(uint), models.ActivityManageRole,
+        fmt.Sprintf("Updated role %s with permissions %v", role.Name, role.PermissionNames()),
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, newRoleResponse(role))
+}
+
+func (h *Handler) HandleDeleteRole(c *gin.Context) {
+    name := models.Role(c.Param("name"))
+    if err := h.service.DeleteRole(name); err != nil {
+        c.JSON(roleErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log xóa role
+    adminID, _ := c.Get("user_id")
+    h.service.LogActivity(adminID.(uint), models.ActivityManageRole,
+        fmt.Sprintf("Deleted role %s", name),
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "role deleted successfully"})
+}
+
+func (h *Handler) HandleForgotPassword(c *gin.Context) {
+    var req ForgotPasswordRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Lỗi chỉ xảy ra với email đã đăng ký nên chỉ ghi log, trả lỗi sẽ tiết lộ email nào tồn tại
+    if err := h.service.RequestPasswordReset(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+        logging.FromContext(c.Request.Context()).Error("Failed to process password reset request", map[string]interface{}{
+            "error": err.Error(),
+        })
+    }
+    
+    // Luôn trả về cùng một thông báo dù email có tồn tại hay không
+    c.JSON(http.StatusOK, gin.H{"message": "if the email is registered, a password reset link has been sent"})
+}
+
+func (h *Handler) HandleResetPasswordWithToken(c *gin.Context) {
+    var req ResetPasswordWithTokenRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    if err := h.service.ResetPasswordWithToken(req.Token, req.Password, c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+        status := http.StatusInternalServerError
+        switch err {
+        case ErrInvalidResetToken, ErrWeakPassword:
+            status = http.StatusBadRequest
+        }
+        c.JSON(status, gin.H{"error": err.Error()})
+        return
+    }
+    
+    c.JSON(http.StatusOK, gin.H{"message": "password reset successfully"})
+}
+
+func (h *Handler) HandleRegister(c *gin.Context) {
+    var req RegisterRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    user, err := h.service.Register(c.Request.Context(), RegisterInput{
+        Email:    req.Email,
+        Username: req.Username,
+        Password: req.Password,
+        FullName: req.FullName,
+        Phone:    req.Phone,
+    }, c.ClientIP(), c.GetHeader("User-Agent"))
+    if err != nil {
+        status := http.StatusInternalServerError
+        switch err {
+        case ErrInvalidEmail, ErrInvalidUsername, ErrWeakPassword:
+            status = http.StatusBadRequest
+        case ErrEmailTaken, ErrUsernameTaken:
+            status = http.StatusConflict
+        }
+        c.JSON(status, gin.H{"error": err.Error()})
+        return
+    }
+    
+    c.JSON(http.StatusCreated, gin.H{
+        "message": "registration successful, please check your email to verify your account",
+        "user": UserResponse{
+            ID:       user.ID,
+            Email:    user.Email,
+            Username: user.Username,
+            Role:     user.Role,
+        },
+    })
+}
+
+func (h *Handler) HandleVerifyEmail(c *gin.Context) {
+    var req VerifyEmailRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    if err := h.service.VerifyEmail(req.Token, c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+        status := http.StatusInternalServerError
+        if err == ErrInvalidVerificationToken {
+            status = http.StatusBadRequest
+        }
+        c.JSON(status, gin.H{"error": err.Error()})
+        return
+    }
+    
+    c.JSON(http.StatusOK, gin.H{"message": "email verified successfully, you can now log in"})
+}
+
+func (h *Handler) HandleResendVerification(c *gin.Context) {
+    var req ResendVerificationRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    if err := h.service.ResendVerification(c.Request.Context(), req.Email); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process request"})
+        return
+    }
+    
+    c.JSON(http.StatusOK, gin.H{"message": "if the account is pending verification, a new link has been sent"})
+}
+
+func newUserDetailResponse(user *models.User) UserDetailResponse {
+    response := UserDetailResponse{
+        ID:              user.ID,
+        Email:           user.Email,
+        Username:        user.Username,
+        Role:            user.Role,
+        Active:          user.Active,
+        Locked:          user.LockedUntil != nil && user.LockedUntil.After(time.Now()),
+        LockedUntil:     user.LockedUntil,
+        LastLogin:       user.LastLogin,
+        EmailVerifiedAt: user.EmailVerifiedAt,
+        TOTPEnabled:     user.TOTPEnabled,
+        CreatedAt:       user.CreatedAt,
+        UpdatedAt:       user.UpdatedAt,
+        Profile: ProfileResponse{
+            FullName: user.Profile.FullName,
+            Phone:    user.Profile.Phone,
+            Address:  user.Profile.Address,
+        },
+    }
+    if user.DeletedAt.Valid {
+        response.DeletedAt = &user.DeletedAt.Time
+    }
+    return response
+}
+
+// userErrorStatus chuyển lỗi quản lý user sang HTTP status tương ứng
+func userErrorStatus(err error) int {
+    switch err {
+    case ErrUserNotFound, ErrRoleNotFound:
+        return http.StatusNotFound
+    case ErrEmailTaken, ErrUsernameTaken, ErrUserNotDeleted:
+        return http.StatusConflict
+    case ErrCannotModifySuperAdmin, ErrCannotModifySelf:
+        return http.StatusForbidden
+    case ErrInvalidEmail, ErrInvalidUsername:
+        return http.StatusBadRequest
+    }
+    return http.StatusInternalServerError
+}
+
+// parseUserID đọc tham số :id trên URL
+func parseUserID(c *gin.Context) (uint, bool) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil || id == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+        return 0, false
+    }
+    return uint(id), true
+}
+
+// parseBoolQuery đọc tham số boolean tùy chọn trên query string
+func parseBoolQuery(c *gin.Context, key string) *bool {
+    value, err := strconv.ParseBool(c.Query(key))
+    if err != nil {
+        return nil
+    }
+    return &value
+}
+
+func (h *Handler) HandleListUsers(c *gin.Context) {
+    params := pagination.Extract(c)
+    
+    filter := repository.UserFilter{
+        Role:   models.Role(c.Query("role")),
+        Active: parseBoolQuery(c, "active"),
+        Locked: parseBoolQuery(c, "locked"),
+        Search: strings.TrimSpace(c.Query("search")),
+    }
+    if deleted := parseBoolQuery(c, "deleted"); deleted != nil {
+        filter.Deleted = *deleted
+    }
+    
+    users, total, err := h.service.ListUsers(filter, params)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    
+    params.Total = total
+    
+    responses := make([]UserDetailResponse, 0, len(users))
+    for i := range users {
+        responses = append(responses, newUserDetailResponse(&users[i]))
+    }
+    
+    c.JSON(http.StatusOK, pagination.NewResponse(responses, params))
+}
+
+func (h *Handler) HandleGetUser(c *gin.Context) {
+    id, ok := parseUserID(c)
+    if !ok {
+        return
+    }
+    
+    user, err := h.service.GetUser(id, true)
+    if err != nil {
+        c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    c.JSON(http.StatusOK, newUserDetailResponse(user))
+}
+
+func (h *Handler) HandleUpdateUser(c *gin.Context) {
+    id, ok := parseUserID(c)
+    if !ok {
+        return
+    }
+    
+    var req UpdateUserRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    input := UpdateUserInput{
+        Email:    req.Email,
+        Username: req.Username,
+        Role:     req.Role,
+        Active:   req.Active,
+    }
+    if req.Profile != nil {
+        input.FullName = req.Profile.FullName
+        input.Phone = req.Profile.Phone
+        input.Address = req.Profile.Address
+    }
+    
+    adminID, _ := c.Get("user_id")
+    user, changes, err := h.service.UpdateUser(adminID.(uint), id, input)
+    if err != nil {
+        c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log kèm các trường đã thay đổi
+    if len(changes) > 0 {
+        h.service.LogActivityWithChanges(adminID.(uint), models.ActivityUpdateUser,
+            fmt.Sprintf("Updated user ID: %d", id), changes,
+            c.ClientIP(), c.GetHeader("User-Agent"))
+    }
+    
+    c.JSON(http.StatusOK, newUserDetailResponse(user))
+}
+
+func (h *Handler) HandleDeleteUser(c *gin.Context) {
+    id, ok := parseUserID(c)
+    if !ok {
+        return
+    }
+    
+    adminID, _ := c.Get("user_id")
+    user, err := h.service.DeleteUser(adminID.(uint), id)
+    if err != nil {
+        c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log xóa user
+    h.service.LogActivityWithChanges(adminID.(uint), models.ActivityDeleteUser,
+        fmt.Sprintf("Deleted user: %s (ID: %d)", user.Username, user.ID),
+        map[string]models.FieldChange{"deleted": {Before: false, After: true}},
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
+}
+
+func (h *Handler) HandleRestoreUser(c *gin.Context) {
+    id, ok := parseUserID(c)
+    if !ok {
+        return
+    }
+    
+    user, err := h.service.RestoreUser(id)
+    if err != nil {
+        c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log khôi phục user
+    adminID, _ := c.Get("user_id")
+    h.service.LogActivityWithChanges(adminID.(uint), models.ActivityRestoreUser,
+        fmt.Sprintf("Restored user: %s (ID: %d)", user.Username, user.ID),
+        map[string]models.FieldChange{"deleted": {Before: true, After: false}},
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, newUserDetailResponse(user))
+}
+
+func (h *Handler) HandleUpdateProfile(c *gin.Context) {
+    var req UpdateProfileRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    userID, _ := c.Get("user_id")
+    user, changes, err := h.service.UpdateProfile(userID.(uint), UpdateProfileInput{
+        FullName: req.FullName,
+        Phone:    req.Phone,
+        Address:  req.Address,
+    })
+    if err != nil {
+        c.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    if len(changes) > 0 {
+        h.service.LogActivityWithChanges(userID.(uint), models.ActivityUpdateProfile, "Updated own profile", changes,
+            c.ClientIP(), c.GetHeader("User-Agent"))
+    }
+    
+    c.JSON(http.StatusOK, ProfileResponse{
+        FullName: user.Profile.FullName,
+        Phone:    user.Profile.Phone,
+        Address:  user.Profile.Address,
+    })
+}
+
+func (h *Handler) HandleChangePassword(c *gin.Context) {
+    var req ChangePasswordRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    userID, _ := c.Get("user_id")
+    sessionID, _ := c.Get("session_id")
+    err := h.service.ChangePassword(c.Request.Context(), userID.(uint), sessionID.(string), req.CurrentPassword, req.NewPassword, c.ClientIP())
+    if err != nil {
+        status := http.StatusInternalServerError
+        switch {
+        case err == ErrIncorrectPassword, err == ErrWeakPassword:
+            status = http.StatusBadRequest
+        case err == ErrUserNotFound:
+            status = http.StatusNotFound
+        case errors.Is(err, ErrAccountLocked):
+            status = http.StatusTooManyRequests
+        }
+        c.JSON(status, gin.H{"error": err.Error()})
+        return
+    }
+    
+    h.service.LogActivity(userID.(uint), models.ActivityChangePassword, "Changed own password, other sessions revoked",
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "password changed successfully"})
+}
+
+func newAPIKeyResponse(key *models.APIKey) APIKeyResponse {
+    return APIKeyResponse{
+        ID:          key.ID,
+        Name:        key.Name,
+        Prefix:      key.Prefix,
+        UserID:      key.UserID,
+        CreatedBy:   key.CreatedBy,
+        Permissions: key.PermissionNames(),
+        ExpiresAt:   key.ExpiresAt,
+        LastUsedAt:  key.LastUsedAt,
+        LastUsedIP:  key.LastUsedIP,
+        RevokedAt:   key.RevokedAt,
+        CreatedAt:   key.CreatedAt,
+    }
+}
+
+// apiKeyErrorStatus chuyển lỗi quản lý API key sang HTTP status tương ứng
+func apiKeyErrorStatus(err error) int {
+    switch err {
+    case ErrAPIKeyNotFound, ErrUserNotFound:
+        return http.StatusNotFound
+    case ErrAPIKeyPermissions, ErrInvalidPermission, ErrPermissionNotInRole, ErrInvalidAPIKeyExpiry, ErrAccountDisabled:
+        return http.StatusBadRequest
+    }
+    return http.StatusInternalServerError
+}
+
+func (h *Handler) HandleListAPIKeys(c *gin.Context) {
+    var filter repository.APIKeyFilter
+    if userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64); err == nil {
+        id := uint(userID)
+        filter.UserID = &id
+    }
+    if includeRevoked := parseBoolQuery(c, "include_revoked"); includeRevoked != nil {
+        filter.IncludeRevoked = *includeRevoked
+    }
+    
+    keys, err := h.service.ListAPIKeys(filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    
+    responses := make([]APIKeyResponse, 0, len(keys))
+    for i := range keys {
+        responses = append(responses, newAPIKeyResponse(&keys[i]))
+    }
+    
+    c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+func (h *Handler) HandleCreateAPIKey(c *gin.Context) {
+    var req CreateAPIKeyRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    
+    adminID, _ := c.Get("user_id")
+    key, rawKey, err := h.service.CreateAPIKey(adminID.(uint), CreateAPIKeyInput{
+        Name:        strings.TrimSpace(req.Name),
+        UserID:      req.UserID,
+        Permissions: req.Permissions,
+        ExpiresAt:   req.ExpiresAt,
+    })
+    if err != nil {
+        c.JSON(apiKeyErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    // Ghi log tạo API key, không bao giờ ghi secret
+    h.service.LogActivity(adminID.(uint), models.ActivityManageAPIKey,
+        fmt.Sprintf("Created API key %s (%s) for user %d with permissions %v", key.Name, key.Prefix, key.UserID, key.PermissionNames()),
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKeyResponse: newAPIKeyResponse(key), Key: rawKey})
+}
+
+func (h *Handler) HandleRevokeAPIKey(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil || id == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid API key id"})
+        return
+    }
+    
+    key, err := h.service.RevokeAPIKey(uint(id))
+    if err != nil {
+        c.JSON(apiKeyErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    
+    adminID, _ := c.Get("user_id")
+    h.service.LogActivity(adminID.(uint), models.ActivityManageAPIKey,
+        fmt.Sprintf("Revoked API key %s (%s) of user %d", key.Name, key.Prefix, key.UserID),
+        c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
@@ -0,0 +1,337 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/yourusername/tastygo/internal/logging"
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/repository"
+)
+
+var (
+	ErrInvalidMFACode      = errors.New("invalid two-factor code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa challenge")
+	ErrMFANotEnrolled      = errors.New("two-factor authentication is not enrolled")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFARequiredByPolicy = errors.New("two-factor authentication is required for your role")
+)
+
+// Số lượng mã khôi phục được tạo khi bật 2FA
+const recoveryCodeCount = 10
+
+const mfaChallengePurpose = "mfa_challenge"
+
+// MFAChallengeClaims là claims của challenge token trả về khi user cần nhập mã 2FA
+type MFAChallengeClaims struct {
+	UserID  uint   `json:"user_id"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// MFAEnrollment chứa secret và otpauth URI để user thêm vào ứng dụng authenticator
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// newMFAChallenge tạo challenge token ngắn hạn cho bước xác thực thứ hai
+func (s *Service) newMFAChallenge(userID uint) (string, error) {
+	now := time.Now()
+	claims := &MFAChallengeClaims{
+		UserID:  userID,
+		Purpose: mfaChallengePurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.MFAChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.config.JWTSecret)
+}
+
+// parseMFAChallenge kiểm tra challenge token và trả về user ID
+func (s *Service) parseMFAChallenge(tokenString string) (uint, error) {
+	claims := &MFAChallengeClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.config.JWTSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid || claims.Purpose != mfaChallengePurpose {
+		return 0, ErrInvalidMFAChallenge
+	}
+	return claims.UserID, nil
+}
+
+// VerifyMFA hoàn tất đăng nhập bằng mã TOTP hoặc mã khôi phục
+func (s *Service) VerifyMFA(ctx context.Context, challengeToken, code, recoveryCode string, ipAddress, userAgent string) (*TokenPair, error) {
+	userID, err := s.parseMFAChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repos.Users.FindByID(userID)
+	if err != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	if err := checkLocked(ctx, *user, ipAddress); err != nil {
+		return nil, err
+	}
+
+	if !user.Active {
+		return nil, ErrAccountDisabled
+	}
+
+	if !user.TOTPEnabled {
+		return nil, ErrMFANotEnrolled
+	}
+
+	verified := false
+	if code != "" {
+		if counter, ok := validateTOTP(user.TOTPSecret, code, user.TOTPLastCounter, time.Now()); ok {
+			user.TOTPLastCounter = counter
+			verified = true
+		}
+	} else if recoveryCode != "" {
+		verified = s.useRecoveryCode(user.ID, recoveryCode)
+		if verified {
+			logging.FromContext(ctx).Warn("Recovery code used for login", map[string]interface{}{
+				"user_id": user.ID,
+				"ip":      ipAddress,
+			})
+		}
+	}
+
+	if !verified {
+		s.recordFailedLogin(ctx, user, ipAddress)
+		logging.FromContext(ctx).Warn("Login failed: invalid two-factor code", map[string]interface{}{
+			"user_id":      user.ID,
+			"ip":           ipAddress,
+			"failed_count": user.FailedLoginCount,
+		})
+		return nil, ErrInvalidMFACode
+	}
+
+	return s.completeLogin(ctx, user, ipAddress, userAgent)
+}
+
+// EnrollMFA tạo secret TOTP mới (chưa kích hoạt) cho user
+func (s *Service) EnrollMFA(userID uint) (*MFAEnrollment, error) {
+	user, err := s.repos.Users.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastCounter = 0
+	if err := s.repos.Users.Save(user); err != nil {
+		return nil, err
+	}
+	s.invalidateUserCache(user.ID)
+
+	return &MFAEnrollment{
+		Secret: secret,
+		URI:    totpURI(secret, user.Email),
+	}, nil
+}
+
+// ConfirmMFA kích hoạt 2FA sau khi user nhập đúng mã đầu tiên và trả về mã khôi phục
+func (s *Service) ConfirmMFA(userID uint, code string) ([]string, error) {
+	user, err := s.repos.Users.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+
+	counter, ok := validateTOTP(user.TOTPSecret, code, user.TOTPLastCounter, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	var codes []string
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		user.TOTPEnabled = true
+		user.TOTPLastCounter = counter
+		if err := tx.Users.Save(user); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateUserCache(user.ID)
+
+	return codes, nil
+}
+
+// DisableMFA tắt 2FA theo yêu cầu của chính user (cần mật khẩu và mã TOTP hiện tại).
+// Mật khẩu hoặc mã sai được tính vào số lần đăng nhập sai, tài khoản bị khóa thì bị từ chối
+func (s *Service) DisableMFA(ctx context.Context, userID uint, password, code, ipAddress string) error {
+	user, err := s.repos.Users.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if !user.TOTPEnabled {
+		return ErrMFANotEnrolled
+	}
+	if err := checkLocked(ctx, *user, ipAddress); err != nil {
+		return err
+	}
+	if !user.CheckPassword(password) {
+		s.recordFailedLogin(ctx, user, ipAddress)
+		logging.FromContext(ctx).Warn("Disabling 2FA failed: incorrect password", map[string]interface{}{
+			"user_id":      user.ID,
+			"ip":           ipAddress,
+			"failed_count": user.FailedLoginCount,
+		})
+		return ErrInvalidCredentials
+	}
+	counter, ok := validateTOTP(user.TOTPSecret, code, user.TOTPLastCounter, time.Now())
+	if !ok {
+		s.recordFailedLogin(ctx, user, ipAddress)
+		logging.FromContext(ctx).Warn("Disabling 2FA failed: invalid code", map[string]interface{}{
+			"user_id":      user.ID,
+			"ip":           ipAddress,
+			"failed_count": user.FailedLoginCount,
+		})
+		return ErrInvalidMFACode
+	}
+	// Đánh dấu mã đã dùng trước mọi kiểm tra tiếp theo để mã không thể dùng lại trong cùng khung thời gian
+	if err := s.repos.Users.UpdateFields(user.ID, map[string]interface{}{"totp_last_counter": counter}); err != nil {
+		return err
+	}
+	s.invalidateUserCache(user.ID)
+	if s.roleRequiresMFA(user.Role) {
+		return ErrMFARequiredByPolicy
+	}
+
+	return s.clearMFA(user.ID)
+}
+
+// ResetMFA xóa cấu hình 2FA của user (dùng khi user mất thiết bị) và thu hồi mọi phiên đăng nhập
+// của user đó. Không áp dụng cho SuperAdmin và cho chính người thực hiện, vốn phải tự tắt 2FA
+// bằng mật khẩu và mã TOTP
+func (s *Service) ResetMFA(actorID, userID uint) error {
+	user, err := s.repos.Users.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.Role == models.RoleSuperAdmin {
+		return ErrCannotModifySuperAdmin
+	}
+	if user.ID == actorID {
+		return ErrCannotModifySelf
+	}
+
+	if err := s.clearMFA(user.ID); err != nil {
+		return err
+	}
+	// Phiên hiện có được tạo khi 2FA còn bảo vệ tài khoản, sau khi reset phải đăng nhập lại
+	_, err = s.RevokeAllSessions(user.ID)
+	return err
+}
+
+// clearMFA xóa secret TOTP và toàn bộ mã khôi phục của user
+func (s *Service) clearMFA(userID uint) error {
+	err := s.repos.Transaction(func(tx repository.Repositories) error {
+		err := tx.Users.UpdateFields(userID, map[string]interface{}{
+			"totp_secret":       "",
+			"totp_enabled":      false,
+			"totp_last_counter": 0,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.MFA.DeleteRecoveryCodes(userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateUserCache(userID)
+	return nil
+}
+
+// replaceRecoveryCodes xóa mã khôi phục cũ và tạo bộ mã mới, chỉ lưu hash
+func replaceRecoveryCodes(tx repository.Repositories, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := hex.EncodeToString(raw)
+		code = code[:5] + "-" + code[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := tx.MFA.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// useRecoveryCode đánh dấu mã khôi phục đã sử dụng, trả về false nếu mã không hợp lệ
+func (s *Service) useRecoveryCode(userID uint, code string) bool {
+	used, err := s.repos.MFA.UseRecoveryCode(userID, hashToken(normalizeRecoveryCode(code)), time.Now())
+	return err == nil && used
+}
+
+// normalizeRecoveryCode bỏ dấu gạch và khoảng trắng để user nhập linh hoạt hơn
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// roleRequiresMFA kiểm tra role có bắt buộc bật 2FA theo chính sách không
+func (s *Service) roleRequiresMFA(role models.Role) bool {
+	policy, err := s.repos.MFA.FindPolicy(role)
+	if err != nil {
+		return false
+	}
+	return policy.Required
+}
+
+// mfaEnrollmentRequired kiểm tra user có đang bị buộc phải bật 2FA không
+func (s *Service) mfaEnrollmentRequired(user models.User) bool {
+	return !user.TOTPEnabled && s.roleRequiresMFA(user.Role)
+}
+
+// ListMFAPolicies trả về chính sách 2FA của tất cả các role
+func (s *Service) ListMFAPolicies() ([]models.MFAPolicy, error) {
+	return s.repos.MFA.ListPolicies()
+}
+
+// SetMFAPolicy bật/tắt yêu cầu 2FA bắt buộc cho một role
+func (s *Service) SetMFAPolicy(role models.Role, required bool) error {
+	policy := models.MFAPolicy{Role: role, Required: required}
+	return s.repos.MFA.SavePolicy(&policy)
+}
@@ -5,35 +5,87 @@ import (
     "strings"
 
     "github.com/gin-gonic/gin"
+    "github.com/yourusername/tastygo/internal/logging"
     "github.com/yourusername/tastygo/internal/models"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// Các route vẫn truy cập được khi user chưa hoàn tất đăng ký 2FA bắt buộc
+var mfaEnrollmentRoutes = map[string]bool{
+    "/api/auth/logout":      true,
+    "/api/auth/mfa/enroll":  true,
+    "/api/auth/mfa/confirm": true,
+}
+
+// AuthMiddleware xác thực access token trong header Authorization. Khi chế độ cookie session
+// được bật và request không có header, token được đọc từ cookie; khi đó request thay đổi dữ liệu
+// phải kèm CSRF token. API key ("Authorization: ApiKey <key>" hoặc X-API-Key) được chấp nhận
+// thay cho access token, request khi đó chỉ có các quyền được gán cho key
+func (h *Handler) AuthMiddleware() gin.HandlerFunc {
     return func(c *gin.Context) {
+        if apiKey := requestAPIKey(c); apiKey != "" {
+            h.authenticateAPIKey(c, apiKey)
+            return
+        }
+        
+        var tokenString string
         authHeader := c.GetHeader("Authorization")
-        if authHeader == "" {
+        if authHeader != "" {
+            parts := strings.Split(authHeader, " ")
+            if len(parts) != 2 || parts[0] != "Bearer" {
+                c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization format"})
+                c.Abort()
+                return
+            }
+            tokenString = parts[1]
+        } else if tokenString = h.sessionCookie(c, AccessCookieName); tokenString != "" {
+            // Trình duyệt tự gửi cookie kèm request từ trang khác, CSRF token chứng minh
+            // request đến từ dashboard
+            if !safeMethod(c.Request.Method) && !h.validCSRF(c) {
+                c.JSON(http.StatusForbidden, gin.H{"error": ErrInvalidCSRFToken.Error()})
+                c.Abort()
+                return
+            }
+        } else {
             c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
             c.Abort()
             return
         }
         
-        parts := strings.Split(authHeader, " ")
-        if len(parts) != 2 || parts[0] != "Bearer" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization format"})
+        claims, err := h.service.ValidateToken(tokenString)
+        if err != nil {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
             c.Abort()
             return
         }
+        logging.RequestFromContext(c.Request.Context()).SetUserID(claims.UserID)
         
-        tokenString := parts[1]
-        claims, err := ValidateToken(tokenString)
-        if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        // User thuộc role bắt buộc 2FA nhưng chưa bật: chỉ cho phép truy cập các route đăng ký 2FA
+        if claims.MFAPending && !mfaEnrollmentRoutes[c.FullPath()] {
+            c.JSON(http.StatusForbidden, gin.H{
+                "error":                   "two-factor authentication enrollment required",
+                "mfa_enrollment_required": true,
+            })
             c.Abort()
             return
         }
         
         c.Set("user_id", claims.UserID)
         c.Set("role", claims.Role)
+        c.Set("session_id", claims.SessionID)
+        c.Set("access_token", tokenString)
+        c.Next()
+    }
+}
+
+// SessionOnly từ chối request dùng API key, dành cho các route quản lý tài khoản của chính
+// user (đổi mật khẩu, 2FA, phiên đăng nhập) vốn chỉ được thực hiện sau khi đăng nhập
+func SessionOnly() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if _, viaAPIKey := c.Get("api_key_id"); viaAPIKey {
+            c.JSON(http.StatusForbidden, gin.H{"error": ErrUserSessionRequired.Error()})
+            c.Abort()
+            return
+        }
         c.Next()
     }
 }
@@ -47,6 +99,13 @@ func RoleMiddleware(roles ...models.Role) gin.HandlerFunc {
             return
         }
         
+        // API key chỉ mang tập quyền được gán, không mang đầy đủ role của user sở hữu
+        if _, viaAPIKey := c.Get("api_key_id"); viaAPIKey {
+            c.JSON(http.StatusForbidden, gin.H{"error": ErrUserSessionRequired.Error()})
+            c.Abort()
+            return
+        }
+        
         userRole := roleInterface.(models.Role)
         
         for _, role := range roles {
@@ -59,4 +118,73 @@ func RoleMiddleware(roles ...models.Role) gin.HandlerFunc {
         c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
         c.Abort()
     }
-}
\ No newline at end of file
+}
+
+// RequirePermission chỉ cho phép request đi tiếp nếu role của user có đủ các quyền yêu cầu.
+// Request dùng API key còn phải được gán các quyền đó cho key
+func (h *Handler) RequirePermission(permissions ...models.Permission) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        roleInterface, exists := c.Get("role")
+        if !exists {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+            c.Abort()
+            return
+        }
+        
+        userRole := roleInterface.(models.Role)
+        keyPermissions, viaAPIKey := c.Get("api_key_permissions")
+        
+        for _, permission := range permissions {
+            granted := h.service.HasPermission(userRole, permission)
+            if viaAPIKey {
+                granted = granted && keyPermissions.(map[models.Permission]bool)[permission]
+            }
+            if !granted {
+                c.JSON(http.StatusForbidden, gin.H{
+                    "error":      "insufficient permissions",
+                    "permission": permission,
+                })
+                c.Abort()
+                return
+            }
+        }
+        
+        c.Next()
+    }
+}
+
+// requestAPIKey đọc API key từ header "Authorization: ApiKey <key>", hoặc từ X-API-Key khi
+// request không có header Authorization
+func requestAPIKey(c *gin.Context) string {
+    authHeader := c.GetHeader("Authorization")
+    if authHeader == "" {
+        return c.GetHeader("X-API-Key")
+    }
+    if scheme, key, ok := strings.Cut(authHeader, " "); ok && scheme == "ApiKey" {
+        return key
+    }
+    return ""
+}
+
+// authenticateAPIKey xác thực request bằng API key và ghi nhận lần sử dụng key
+func (h *Handler) authenticateAPIKey(c *gin.Context, rawKey string) {
+    key, owner, err := h.service.AuthenticateAPIKey(rawKey)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        c.Abort()
+        return
+    }
+    logging.RequestFromContext(c.Request.Context()).SetUserID(owner.ID)
+    h.service.RecordAPIKeyUsage(key, c.Request.Method, c.Request.URL.Path, c.ClientIP(), c.GetHeader("User-Agent"))
+    
+    permissions := make(map[models.Permission]bool, len(key.Permissions))
+    for _, permission := range key.PermissionNames() {
+        permissions[permission] = true
+    }
+    
+    c.Set("user_id", owner.ID)
+    c.Set("role", owner.Role)
+    c.Set("api_key_id", key.ID)
+    c.Set("api_key_permissions", permissions)
+    c.Next()
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/logging"
+	"github.com/yourusername/tastygo/internal/mailer"
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/repository"
+	"github.com/yourusername/tastygo/pkg/validator"
+)
+
+var (
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	ErrWeakPassword      = errors.New("password must be at least 8 characters and contain upper, lower, number and special characters")
+)
+
+// RequestPasswordReset tạo token đặt lại mật khẩu và gửi email cho user.
+// Hàm không trả lỗi khi email không tồn tại để tránh lộ thông tin tài khoản.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string, ipAddress string) error {
+	user, err := s.repos.Users.FindByEmail(normalizeEmail(email))
+	if err != nil {
+		logging.FromContext(ctx).Info("Password reset requested for unknown email", map[string]interface{}{
+			"ip": ipAddress,
+		})
+		return nil
+	}
+
+	if !user.Active {
+		return nil
+	}
+
+	token, tokenHash, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		// Vô hiệu hóa các token cũ chưa dùng, chỉ link mới nhất có hiệu lực
+		if err := tx.Tokens.InvalidatePasswordResets(user.ID, time.Now()); err != nil {
+			return err
+		}
+
+		return tx.Tokens.CreatePasswordReset(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(s.config.PasswordResetTTL),
+			IPAddress: ipAddress,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.config.FrontendURL + "/reset-password?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "TastyGo password reset",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your TastyGo password.\n"+
+			"Open the link below within %d minutes to choose a new password:\n\n%s\n\n"+
+			"If you did not request this, you can ignore this email.\n",
+			user.Username, int(s.config.PasswordResetTTL.Minutes()), link),
+	}
+
+	// Gửi email bất đồng bộ để thời gian phản hồi không phụ thuộc vào việc email có tồn tại
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logging.FromContext(ctx).Error("Failed to send password reset email", map[string]interface{}{
+				"user_id": user.ID,
+				"error":   err.Error(),
+			})
+		}
+	}()
+
+	return nil
+}
+
+// ResetPasswordWithToken đặt mật khẩu mới bằng token trong email và thu hồi mọi phiên đăng nhập
+func (s *Service) ResetPasswordWithToken(token, newPassword string, ipAddress, userAgent string) error {
+	if !validator.IsStrongPassword(newPassword) {
+		return ErrWeakPassword
+	}
+
+	resetToken, err := s.repos.Tokens.FindValidPasswordReset(hashToken(token), time.Now())
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.repos.Users.FindByID(resetToken.UserID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	if err := user.SetPassword(newPassword); err != nil {
+		return err
+	}
+	user.FailedLoginCount = 0
+	user.LockedUntil = nil
+
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		// Token chỉ được dùng một lần kể cả khi có request đồng thời
+		used, err := tx.Tokens.UsePasswordReset(resetToken.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidResetToken
+		}
+
+		return tx.Users.Save(user)
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateUserCache(user.ID)
+
+	if _, err := s.RevokeAllSessions(user.ID); err != nil {
+		return err
+	}
+
+	s.LogActivity(user.ID, models.ActivityResetPassword, "Reset password via email link", ipAddress, userAgent)
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"errors"
+	"context"
+
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/repository"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrRoleInUse         = errors.New("role is assigned to one or more users")
+	ErrSystemRole        = errors.New("system role cannot be modified")
+	ErrInvalidPermission = errors.New("invalid permission")
+)
+
+// RolePermissions trả về tập quyền của role (có cache)
+func (s *Service) RolePermissions(role models.Role) (map[models.Permission]bool, error) {
+	var permissions map[models.Permission]bool
+	err := s.cache.GetOrLoad(context.Background(), rolePermissionsCacheKey(role), &permissions, rolePermissionsCacheOptions(role),
+		func(ctx context.Context) (interface{}, error) {
+			rows, err := s.repos.Roles.Permissions(role)
+			if err != nil {
+				return nil, err
+			}
+
+			loaded := make(map[models.Permission]bool, len(rows))
+			for _, permission := range rows {
+				loaded[permission] = true
+			}
+			return loaded, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// HasPermission kiểm tra role có quyền được yêu cầu không
+func (s *Service) HasPermission(role models.Role, permission models.Permission) bool {
+	permissions, err := s.RolePermissions(role)
+	if err != nil {
+		return false
+	}
+	return permissions[models.PermAll] || permissions[permission]
+}
+
+// RoleExists kiểm tra role đã được định nghĩa trong database chưa
+func (s *Service) RoleExists(role models.Role) bool {
+	exists, err := s.repos.Roles.Exists(role)
+	return err == nil && exists
+}
+
+// ListRoles trả về tất cả role cùng tập quyền
+func (s *Service) ListRoles() ([]models.RoleDefinition, error) {
+	return s.repos.Roles.List()
+}
+
+// GetRole trả về một role theo tên
+func (s *Service) GetRole(name models.Role) (*models.RoleDefinition, error) {
+	role, err := s.repos.Roles.Find(name)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// CreateRole tạo role tùy chỉnh mới
+func (s *Service) CreateRole(name models.Role, description string, permissions []models.Permission) (*models.RoleDefinition, error) {
+	if err := validatePermissions(permissions); err != nil {
+		return nil, err
+	}
+	if s.RoleExists(name) {
+		return nil, ErrRoleExists
+	}
+
+	role := models.RoleDefinition{
+		Name:        name,
+		Description: description,
+		Permissions: buildRolePermissions(name, permissions),
+	}
+	if err := s.repos.Roles.Create(&role); err != nil {
+		return nil, err
+	}
+
+	s.invalidateRoleCache(name)
+	return &role, nil
+}
+
+// UpdateRole thay thế mô tả và tập quyền của role
+func (s *Service) UpdateRole(name models.Role, description string, permissions []models.Permission) (*models.RoleDefinition, error) {
+	if err := validatePermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	role, err := s.GetRole(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Không cho phép sửa superadmin để tránh tự khóa quyền quản trị
+	if role.Name == models.RoleSuperAdmin {
+		return nil, ErrSystemRole
+	}
+
+	role.Description = description
+	role.Permissions = buildRolePermissions(name, permissions)
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		return tx.Roles.ReplacePermissions(role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateRoleCache(name)
+	return role, nil
+}
+
+// DeleteRole xóa role tùy chỉnh không còn được gán cho user nào
+func (s *Service) DeleteRole(name models.Role) error {
+	role, err := s.GetRole(name)
+	if err != nil {
+		return err
+	}
+	if role.System {
+		return ErrSystemRole
+	}
+
+	count, err := s.repos.Users.CountByRole(name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		return tx.Roles.Delete(name)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateRoleCache(name)
+	return nil
+}
+
+// validatePermissions đảm bảo mọi quyền đều nằm trong danh sách hợp lệ
+func validatePermissions(permissions []models.Permission) error {
+	for _, permission := range permissions {
+		if !models.IsValidPermission(permission) {
+			return ErrInvalidPermission
+		}
+	}
+	return nil
+}
+
+func buildRolePermissions(role models.Role, permissions []models.Permission) []models.RolePermission {
+	seen := make(map[models.Permission]bool, len(permissions))
+	rows := make([]models.RolePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+		rows = append(rows, models.RolePermission{Role: role, Permission: permission})
+	}
+	return rows
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yourusername/tastygo/internal/logging"
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/pkg/validator"
+)
+
+var ErrIncorrectPassword = errors.New("current password is incorrect")
+
+// UpdateProfileInput chứa các trường profile user được tự chỉnh sửa; nil nghĩa là giữ nguyên
+type UpdateProfileInput struct {
+	FullName *string
+	Phone    *string
+	Address  *string
+}
+
+// UpdateProfile cập nhật profile của chính user và trả về các trường đã thay đổi
+func (s *Service) UpdateProfile(userID uint, input UpdateProfileInput) (*models.User, map[string]models.FieldChange, error) {
+	user, err := s.GetUser(userID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	before := userSnapshot(user)
+
+	if input.FullName != nil {
+		user.Profile.FullName = *input.FullName
+	}
+	if input.Phone != nil {
+		user.Profile.Phone = *input.Phone
+	}
+	if input.Address != nil {
+		user.Profile.Address = *input.Address
+	}
+
+	changes := diffSnapshots(before, userSnapshot(user))
+	if len(changes) == 0 {
+		return user, changes, nil
+	}
+
+	// User tạo trước đây có thể chưa có bản ghi profile
+	user.Profile.UserID = user.ID
+	if err := s.repos.Users.SaveProfile(&user.Profile); err != nil {
+		return nil, nil, err
+	}
+
+	s.invalidateUserCache(user.ID)
+	return user, changes, nil
+}
+
+// ChangePassword đổi mật khẩu của chính user và thu hồi các phiên đăng nhập khác. Nhập sai mật khẩu
+// hiện tại được tính như đăng nhập sai, để access token bị lộ không dùng được để dò mật khẩu
+func (s *Service) ChangePassword(ctx context.Context, userID uint, currentSessionID, currentPassword, newPassword, ipAddress string) error {
+	user, err := s.GetUser(userID, false)
+	if err != nil {
+		return err
+	}
+
+	if err := checkLocked(ctx, *user, ipAddress); err != nil {
+		return err
+	}
+	if !user.CheckPassword(currentPassword) {
+		s.recordFailedLogin(ctx, user, ipAddress)
+		logging.FromContext(ctx).Warn("Password change failed: incorrect current password", map[string]interface{}{
+			"user_id":      user.ID,
+			"ip":           ipAddress,
+			"failed_count": user.FailedLoginCount,
+		})
+		return ErrIncorrectPassword
+	}
+	if !validator.IsStrongPassword(newPassword) {
+		return ErrWeakPassword
+	}
+
+	if err := user.SetPassword(newPassword); err != nil {
+		return err
+	}
+	if err := s.repos.Users.UpdateFields(user.ID, map[string]interface{}{
+		"password_hash":      user.PasswordHash,
+		"failed_login_count": 0,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := s.RevokeOtherSessions(user.ID, currentSessionID); err != nil {
+		return err
+	}
+
+	s.invalidateUserCache(user.ID)
+	return nil
+}
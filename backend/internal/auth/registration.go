@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/logging"
+	"github.com/yourusername/tastygo/internal/mailer"
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/repository"
+	"github.com/yourusername/tastygo/pkg/validator"
+)
+
+var (
+	ErrInvalidEmail             = errors.New("invalid email address")
+	ErrInvalidUsername          = errors.New("username must be 3-32 characters of letters, numbers, '.', '_' or '-'")
+	ErrEmailTaken               = errors.New("email is already registered")
+	ErrUsernameTaken            = errors.New("username is already taken")
+	ErrEmailNotVerified         = errors.New("email address has not been verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+)
+
+// RegisterInput là thông tin khách hàng cung cấp khi đăng ký
+type RegisterInput struct {
+	Email    string
+	Username string
+	Password string
+	FullName string
+	Phone    string
+}
+
+// Register tạo tài khoản khách hàng ở trạng thái chưa kích hoạt và gửi email xác thực
+func (s *Service) Register(ctx context.Context, input RegisterInput, ipAddress, userAgent string) (*models.User, error) {
+	input.Email = normalizeEmail(input.Email)
+	input.Username = strings.TrimSpace(input.Username)
+
+	if !validator.IsValidEmail(input.Email) {
+		return nil, ErrInvalidEmail
+	}
+	if !isValidUsername(input.Username) {
+		return nil, ErrInvalidUsername
+	}
+	if !validator.IsStrongPassword(input.Password) {
+		return nil, ErrWeakPassword
+	}
+
+	// Kiểm tra trùng lặp, kể cả các tài khoản đã bị xóa mềm (unique index vẫn áp dụng)
+	if exists, _ := s.repos.Users.EmailExists(input.Email, 0); exists {
+		return nil, ErrEmailTaken
+	}
+	if exists, _ := s.repos.Users.UsernameExists(input.Username, 0); exists {
+		return nil, ErrUsernameTaken
+	}
+
+	user := models.User{
+		Email:    input.Email,
+		Username: input.Username,
+		Role:     models.RoleCustomer,
+		Profile: models.UserProfile{
+			FullName: input.FullName,
+			Phone:    input.Phone,
+		},
+	}
+	if err := user.SetPassword(input.Password); err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		if err := tx.Users.Create(&user); err != nil {
+			return err
+		}
+
+		// Cột active có default:true nên phải cập nhật riêng sau khi tạo
+		if err := tx.Users.UpdateFields(user.ID, map[string]interface{}{"active": false}); err != nil {
+			return err
+		}
+		user.Active = false
+
+		return tx.Tokens.CreateEmailVerification(&models.EmailVerificationToken{
+			UserID:    user.ID,
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(s.config.EmailVerificationTTL),
+		})
+	})
+	if err != nil {
+		// Hai request đăng ký đồng thời có thể cùng vượt qua bước kiểm tra ở trên
+		if repository.IsUniqueViolation(err) {
+			if strings.Contains(err.Error(), "username") {
+				return nil, ErrUsernameTaken
+			}
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+
+	s.sendVerificationEmail(ctx, user, token)
+
+	s.LogActivity(user.ID, models.ActivityRegister, "Customer self-registration", ipAddress, userAgent)
+
+	return &user, nil
+}
+
+// ResendVerification gửi lại email xác thực cho tài khoản chưa kích hoạt.
+// Không trả lỗi khi email không tồn tại để tránh lộ thông tin tài khoản.
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.repos.Users.FindByEmail(normalizeEmail(email))
+	if err != nil || user.EmailVerifiedAt != nil || user.Active {
+		return nil
+	}
+
+	token, tokenHash, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		if err := tx.Tokens.InvalidateEmailVerifications(user.ID, time.Now()); err != nil {
+			return err
+		}
+
+		return tx.Tokens.CreateEmailVerification(&models.EmailVerificationToken{
+			UserID:    user.ID,
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(s.config.EmailVerificationTTL),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.sendVerificationEmail(ctx, *user, token)
+	return nil
+}
+
+// VerifyEmail kích hoạt tài khoản bằng token trong email xác thực
+func (s *Service) VerifyEmail(token string, ipAddress, userAgent string) error {
+	verification, err := s.repos.Tokens.FindValidEmailVerification(hashToken(token), time.Now())
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	now := time.Now()
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		used, err := tx.Tokens.UseEmailVerification(verification.ID, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidVerificationToken
+		}
+
+		return tx.Users.UpdateFields(verification.UserID, map[string]interface{}{
+			"active":            true,
+			"email_verified_at": now,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateUserCache(verification.UserID)
+
+	s.LogActivity(verification.UserID, models.ActivityVerifyEmail, "Email address verified", ipAddress, userAgent)
+
+	return nil
+}
+
+// sendVerificationEmail gửi link xác thực email (bất đồng bộ)
+func (s *Service) sendVerificationEmail(ctx context.Context, user models.User, token string) {
+	link := s.config.FrontendURL + "/verify-email?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your TastyGo account",
+		Body: fmt.Sprintf("Hi %s,\n\nThanks for signing up for TastyGo.\n"+
+			"Open the link below within %d hours to activate your account:\n\n%s\n",
+			user.Username, int(s.config.EmailVerificationTTL.Hours()), link),
+	}
+
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logging.FromContext(ctx).Error("Failed to send verification email", map[string]interface{}{
+				"user_id": user.ID,
+				"error":   err.Error(),
+			})
+		}
+	}()
+}
+
+// isValidUsername kiểm tra username chỉ gồm ký tự an toàn
+func isValidUsername(username string) bool {
+	if len(username) < 3 || len(username) > 32 {
+		return false
+	}
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -1,48 +1,108 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
-	"math/rand"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/yourusername/tastygo/internal/database"
+	"github.com/yourusername/tastygo/internal/cache"
 	"github.com/yourusername/tastygo/internal/logging"
+	"github.com/yourusername/tastygo/internal/metrics"
+	"github.com/yourusername/tastygo/internal/mailer"
 	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/pagination"
+	"github.com/yourusername/tastygo/internal/repository"
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUserNotFound       = errors.New("user not found")
-	jwtSecret             = []byte(os.Getenv("JWT_SECRET")) // Lấy từ biến môi trường
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrAccountDisabled     = errors.New("account is disabled")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrAccountLocked       = errors.New("account is temporarily locked")
 )
 
-// Thêm hàm khởi tạo JWT secret
-func InitJWTSecret() {
-	secretEnv := os.Getenv("JWT_SECRET")
-	if secretEnv == "" {
-		// Tạo secret ngẫu nhiên nếu không có trong biến môi trường
-		randomBytes := make([]byte, 32)
-		if _, err := rand.Read(randomBytes); err != nil {
-			log.Fatal("Failed to generate random JWT secret")
-		}
-		jwtSecret = randomBytes
-		log.Println("WARNING: Using randomly generated JWT secret. Set JWT_SECRET environment variable for production.")
-	} else {
-		jwtSecret = []byte(secretEnv)
+// Config chứa các tham số của auth.Service
+type Config struct {
+	JWTSecret            []byte
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+	MFAChallengeTTL      time.Duration
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	// FrontendURL là địa chỉ dashboard, dùng để tạo link trong email
+	FrontendURL string
+}
+
+// DefaultConfig trả về cấu hình mặc định, JWTSecret cần được đặt riêng
+func DefaultConfig() Config {
+	return Config{
+		AccessTokenTTL:       15 * time.Minute,
+		RefreshTokenTTL:      7 * 24 * time.Hour,
+		MFAChallengeTTL:      5 * time.Minute,
+		PasswordResetTTL:     time.Hour,
+		EmailVerificationTTL: 24 * time.Hour,
+		FrontendURL:          "http://localhost:3000",
+	}
+}
+
+// ResolveJWTSecret trả về secret đã cấu hình, hoặc tạo secret ngẫu nhiên nếu để trống
+func ResolveJWTSecret(secret string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		logging.Fatal("Failed to generate random JWT secret", nil)
+	}
+	logging.Warn("Using randomly generated JWT secret. Set JWT_SECRET environment variable for production.", nil)
+	return randomBytes
+}
+
+// Service chứa nghiệp vụ xác thực và quản lý user. Mọi phụ thuộc (repository, mailer,
+// cache) được truyền vào qua NewService nên có thể thay bằng fake khi test.
+type Service struct {
+	repos  repository.Repositories
+	mailer mailer.Mailer
+	cache  *cache.Loader
+	config Config
+}
+
+// NewService tạo auth.Service từ các phụ thuộc
+func NewService(repos repository.Repositories, mail mailer.Mailer, c cache.Cache, config Config) *Service {
+	return &Service{
+		repos:  repos,
+		mailer: mail,
+		cache:  cache.NewLoader(c),
+		config: config,
 	}
 }
 
 type TokenClaims struct {
-	UserID uint        `json:"user_id"`
-	Role   models.Role `json:"role"`
+	UserID     uint        `json:"user_id"`
+	Role       models.Role `json:"role"`
+	SessionID  string      `json:"sid"`
+	MFAPending bool        `json:"mfa_pending,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func LogActivity(userID uint, activityType models.ActivityType, description string, ipAddress, userAgent string) {
+// LogActivity ghi một bản ghi activity log
+func (s *Service) LogActivity(userID uint, activityType models.ActivityType, description string, ipAddress, userAgent string) {
+	s.LogActivityWithChanges(userID, activityType, description, nil, ipAddress, userAgent)
+}
+
+// LogActivityWithChanges ghi log kèm danh sách trường thay đổi (trước/sau)
+func (s *Service) LogActivityWithChanges(userID uint, activityType models.ActivityType, description string, changes map[string]models.FieldChange, ipAddress, userAgent string) {
 	log := models.ActivityLog{
 		UserID:       userID,
 		ActivityType: activityType,
@@ -51,68 +111,132 @@ func LogActivity(userID uint, activityType models.ActivityType, description stri
 		UserAgent:    userAgent,
 	}
 	
-	database.DB.Create(&log)
+	if len(changes) > 0 {
+		if data, err := json.Marshal(changes); err == nil {
+			log.Changes = models.JSONText(data)
+		}
+	}
+	
+	s.repos.ActivityLogs.Create(&log)
 }
 
-func Login(email, password string, ipAddress, userAgent string) (string, error) {
-	var user models.User
-	
-	result := database.DB.Where("email = ?", email).First(&user)
-	if result.Error != nil {
-		logging.Warn("Login attempt failed: user not found", map[string]interface{}{
+// ListActivityLogs liệt kê activity log theo bộ lọc và phân trang
+func (s *Service) ListActivityLogs(filter repository.ActivityLogFilter, params pagination.Params) ([]models.ActivityLog, int64, error) {
+	return s.repos.ActivityLogs.List(filter, params)
+}
+
+// TokenPair là kết quả trả về khi đăng nhập hoặc refresh thành công
+type TokenPair struct {
+	AccessToken  string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// LoginResult là kết quả của Login: cặp token, hoặc challenge token khi user bật 2FA
+type LoginResult struct {
+	*TokenPair
+	MFARequired    bool   `json:"mfa_required,omitempty"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+}
+
+func (s *Service) Login(ctx context.Context, email, password string, ipAddress, userAgent string) (*LoginResult, error) {
+	email = normalizeEmail(email)
+	user, err := s.repos.Users.FindByEmail(email)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Login attempt failed: user not found", map[string]interface{}{
 			"email": email,
 			"ip":    ipAddress,
 		})
-		return "", ErrUserNotFound
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginNotFound).Inc()
+		return nil, ErrUserNotFound
 	}
 	
-	// Kiểm tra tài khoản có bị khóa tạm thời không
-	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
-		remainingTime := time.Until(*user.LockedUntil).Minutes()
-		logging.Warn("Login attempt on locked account", map[string]interface{}{
-			"user_id":     user.ID,
-			"email":       user.Email,
-			"ip":          ipAddress,
-			"locked_until": user.LockedUntil,
-		})
-		return "", fmt.Errorf("account is temporarily locked. Try again in %.0f minutes", remainingTime)
+	if err := checkLocked(ctx, *user, ipAddress); err != nil {
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginLocked).Inc()
+		return nil, err
 	}
 	
 	// Kiểm tra mật khẩu
 	if !user.CheckPassword(password) {
-		// Tăng số lần đăng nhập sai
-		now := time.Now()
-		user.LastFailedLogin = &now
-		user.FailedLoginCount++
-		
-		// Nếu sai 5 lần liên tiếp, khóa tài khoản 30 phút
-		if user.FailedLoginCount >= 5 {
-			lockTime := time.Now().Add(30 * time.Minute)
-			user.LockedUntil = &lockTime
-			user.FailedLoginCount = 0
-			logging.Warn("Account locked due to multiple failed login attempts", map[string]interface{}{
-				"user_id":     user.ID,
-				"email":       user.Email,
-				"ip":          ipAddress,
-				"locked_until": lockTime,
-			})
-		}
-		
-		database.DB.Save(&user)
-		logging.Warn("Login failed: invalid password", map[string]interface{}{
+		s.recordFailedLogin(ctx, user, ipAddress)
+		logging.FromContext(ctx).Warn("Login failed: invalid password", map[string]interface{}{
 			"user_id":     user.ID,
 			"email":       user.Email,
 			"ip":          ipAddress,
 			"failed_count": user.FailedLoginCount,
 		})
-		return "", ErrInvalidCredentials
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginBadPassword).Inc()
+		return nil, ErrInvalidCredentials
 	}
 	
 	// Kiểm tra tài khoản có active không
 	if !user.Active {
-		return "", errors.New("account is disabled")
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginDisabled).Inc()
+		// Khách hàng tự đăng ký nhưng chưa xác thực email
+		if user.Role == models.RoleCustomer && user.EmailVerifiedAt == nil {
+			return nil, ErrEmailNotVerified
+		}
+		return nil, ErrAccountDisabled
+	}
+	
+	// User đã bật 2FA: trả về challenge token thay vì session
+	if user.TOTPEnabled {
+		challenge, err := s.newMFAChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginMFARequired).Inc()
+		return &LoginResult{MFARequired: true, ChallengeToken: challenge}, nil
+	}
+	
+	pair, err := s.completeLogin(ctx, user, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	metrics.LoginAttempts.WithLabelValues(metrics.LoginSuccess).Inc()
+	
+	return &LoginResult{TokenPair: pair}, nil
+}
+
+// checkLocked kiểm tra tài khoản có bị khóa tạm thời không
+func checkLocked(ctx context.Context, user models.User, ipAddress string) error {
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		remainingTime := time.Until(*user.LockedUntil).Minutes()
+		logging.FromContext(ctx).Warn("Login attempt on locked account", map[string]interface{}{
+			"user_id":     user.ID,
+			"email":       user.Email,
+			"ip":          ipAddress,
+			"locked_until": user.LockedUntil,
+		})
+		return fmt.Errorf("%w. Try again in %.0f minutes", ErrAccountLocked, remainingTime)
+	}
+	return nil
+}
+
+// recordFailedLogin tăng số lần đăng nhập sai và khóa tài khoản nếu vượt ngưỡng
+func (s *Service) recordFailedLogin(ctx context.Context, user *models.User, ipAddress string) {
+	now := time.Now()
+	user.LastFailedLogin = &now
+	user.FailedLoginCount++
+	
+	// Nếu sai 5 lần liên tiếp, khóa tài khoản 30 phút
+	if user.FailedLoginCount >= 5 {
+		lockTime := time.Now().Add(30 * time.Minute)
+		user.LockedUntil = &lockTime
+		user.FailedLoginCount = 0
+		logging.FromContext(ctx).Warn("Account locked due to multiple failed login attempts", map[string]interface{}{
+			"user_id":     user.ID,
+			"email":       user.Email,
+			"ip":          ipAddress,
+			"locked_until": lockTime,
+		})
 	}
 	
+	s.repos.Users.Save(user)
+}
+
+// completeLogin tạo session sau khi user đã xác thực đầy đủ
+func (s *Service) completeLogin(ctx context.Context, user *models.User, ipAddress, userAgent string) (*TokenPair, error) {
 	// Reset số lần đăng nhập sai
 	user.FailedLoginCount = 0
 	user.LockedUntil = nil
@@ -120,51 +244,160 @@ func Login(email, password string, ipAddress, userAgent string) (string, error)
 	// Update last login
 	now := time.Now()
 	user.LastLogin = &now
-	database.DB.Save(&user)
+	if err := s.repos.Users.Save(user); err != nil {
+		return nil, err
+	}
+	
+	pair, err := s.issueSession(*user, newFamilyID(), now, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Ghi log đăng nhập thành công
+	s.LogActivity(user.ID, models.ActivityLogin, "Successful login", ipAddress, userAgent)
 	
-	// Generate token
-	expirationTime := time.Now().Add(24 * time.Hour)
+	logging.FromContext(ctx).Info("User logged in successfully", map[string]interface{}{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"ip":      ipAddress,
+	})
+	
+	return pair, nil
+}
+
+// issueSession tạo access token + refresh token mới và lưu vào bảng sessions
+func (s *Service) issueSession(user models.User, familyID string, loginAt time.Time, ipAddress, userAgent string) (*TokenPair, error) {
+	now := time.Now()
+	expirationTime := now.Add(s.config.AccessTokenTTL)
 	claims := &TokenClaims{
-		UserID: user.ID,
-		Role:   user.Role,
+		UserID:     user.ID,
+		Role:       user.Role,
+		SessionID:  familyID,
+		MFAPending: s.mfaEnrollmentRequired(user),
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newFamilyID(),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 	
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := token.SignedString(s.config.JWTSecret)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	
+	refreshToken, refreshHash, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
 	}
 	
 	// Save session
 	session := models.Session{
-		UserID:    user.ID,
-		Token:     tokenString,
-		ExpiresAt: expirationTime,
-		IPAddress: ipAddress,
-		UserAgent: userAgent,
+		UserID:           user.ID,
+		Token:            tokenString,
+		ExpiresAt:        expirationTime,
+		IPAddress:        ipAddress,
+		UserAgent:        userAgent,
+		FamilyID:         familyID,
+		LoginAt:          loginAt,
+		RefreshTokenHash: refreshHash,
+		RefreshExpiresAt: now.Add(s.config.RefreshTokenTTL),
+	}
+	
+	if err := s.repos.Sessions.Create(&session); err != nil {
+		return nil, err
 	}
 	
-	database.DB.Create(&session)
+	return &TokenPair{
+		AccessToken:  tokenString,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expirationTime,
+	}, nil
+}
+
+// Refresh đổi refresh token lấy cặp token mới (rotation).
+// Nếu một refresh token đã dùng bị gửi lại, toàn bộ family sẽ bị thu hồi.
+func (s *Service) Refresh(ctx context.Context, refreshToken string, ipAddress, userAgent string) (*TokenPair, error) {
+	session, err := s.repos.Sessions.FindByRefreshHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
 	
-	// Ghi log đăng nhập thành công
-	LogActivity(user.ID, models.ActivityLogin, "Successful login", ipAddress, userAgent)
+	now := time.Now()
 	
-	logging.Info("User logged in successfully", map[string]interface{}{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"ip":      ipAddress,
-	})
+	// Refresh token đã bị rotate hoặc thu hồi mà vẫn được dùng lại => có thể đã bị đánh cắp
+	if session.RotatedAt != nil || session.RevokedAt != nil {
+		s.repos.Sessions.RevokeFamily(session.FamilyID, now)
+		logging.FromContext(ctx).Warn("Refresh token reuse detected, session family revoked", map[string]interface{}{
+			"user_id":   session.UserID,
+			"family_id": session.FamilyID,
+			"ip":        ipAddress,
+		})
+		s.LogActivity(session.UserID, models.ActivityTokenReuse, "Refresh token reuse detected, all sessions in family revoked", ipAddress, userAgent)
+		return nil, ErrRefreshTokenReused
+	}
+	
+	if !session.RefreshExpiresAt.After(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+	
+	user, err := s.repos.Users.FindByID(session.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
 	
-	return tokenString, nil
+	if !user.Active {
+		s.repos.Sessions.RevokeFamily(session.FamilyID, now)
+		return nil, ErrAccountDisabled
+	}
+	
+	// Đánh dấu session cũ đã được rotate, chỉ một request refresh đồng thời được thành công
+	rotated, err := s.repos.Sessions.MarkRotated(session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		s.repos.Sessions.RevokeFamily(session.FamilyID, now)
+		return nil, ErrRefreshTokenReused
+	}
+	
+	pair, err := s.issueSession(*user, session.FamilyID, session.LoginAt, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.LogActivity(user.ID, models.ActivityRefreshToken, "Access token refreshed", ipAddress, userAgent)
+	
+	return pair, nil
+}
+
+// generateOpaqueToken tạo token ngẫu nhiên (refresh token, reset token...) và hash của nó để lưu trữ
+func generateOpaqueToken() (string, string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(randomBytes)
+	return token, hashToken(token), nil
+}
+
+// hashToken trả về SHA-256 của token dạng hex
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-func ValidateToken(tokenString string) (*TokenClaims, error) {
+// newFamilyID tạo ID ngẫu nhiên cho một chuỗi session
+func newFamilyID() string {
+	randomBytes := make([]byte, 16)
+	rand.Read(randomBytes)
+	return hex.EncodeToString(randomBytes)
+}
+
+func (s *Service) ValidateToken(tokenString string) (*TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+		return s.config.JWTSecret, nil
 	})
 	
 	if err != nil {
@@ -173,9 +406,7 @@ func ValidateToken(tokenString string) (*TokenClaims, error) {
 	
 	if claims, ok := token.Claims.(*TokenClaims); ok && token.Valid {
 		// Check if token is in sessions table
-		var session models.Session
-		result := database.DB.Where("token = ? AND expires_at > ?", tokenString, time.Now()).First(&session)
-		if result.Error != nil {
+		if _, err := s.repos.Sessions.FindValidByToken(tokenString, time.Now()); err != nil {
 			return nil, errors.New("invalid or expired session")
 		}
 		
@@ -185,13 +416,51 @@ func ValidateToken(tokenString string) (*TokenClaims, error) {
 	return nil, errors.New("invalid token")
 }
 
-func Logout(tokenString string) error {
+func (s *Service) Logout(tokenString string) error {
 	// Lấy thông tin user từ token
-	claims, _ := ValidateToken(tokenString)
+	claims, _ := s.ValidateToken(tokenString)
 	if claims != nil {
 		// Ghi log đăng xuất
-		LogActivity(claims.UserID, models.ActivityLogout, "User logged out", "", "")
+		s.LogActivity(claims.UserID, models.ActivityLogout, "User logged out", "", "")
+	}
+	
+	// Thu hồi toàn bộ family để refresh token của phiên này cũng không dùng được nữa
+	session, err := s.repos.Sessions.FindByToken(tokenString)
+	if err != nil {
+		return nil
 	}
 	
-	return database.DB.Where("token = ?", tokenString).Delete(&models.Session{}).Error
+	return s.repos.Sessions.RevokeFamily(session.FamilyID, time.Now())
+}
+
+// ListSessions trả về các phiên đăng nhập còn hiệu lực của một user
+func (s *Service) ListSessions(userID uint) ([]models.Session, error) {
+	return s.repos.Sessions.ListActive(userID, time.Now())
+}
+
+// CountActiveSessions đếm số phiên đăng nhập còn hiệu lực của mọi user
+func (s *Service) CountActiveSessions() (int64, error) {
+	return s.repos.Sessions.CountActive(time.Now())
+}
+
+// RevokeSession thu hồi một phiên đăng nhập (theo family ID) của user
+func (s *Service) RevokeSession(userID uint, sessionID string) error {
+	count, err := s.repos.Sessions.RevokeUserFamily(userID, sessionID, time.Now())
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions thu hồi tất cả phiên của user ngoại trừ phiên hiện tại
+func (s *Service) RevokeOtherSessions(userID uint, currentSessionID string) (int64, error) {
+	return s.repos.Sessions.RevokeUserSessions(userID, currentSessionID, time.Now())
+}
+
+// RevokeAllSessions thu hồi toàn bộ phiên đăng nhập của user
+func (s *Service) RevokeAllSessions(userID uint) (int64, error) {
+	return s.repos.Sessions.RevokeUserSessions(userID, "", time.Now())
 }
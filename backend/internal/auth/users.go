@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/pagination"
+	"github.com/yourusername/tastygo/internal/repository"
+	"github.com/yourusername/tastygo/pkg/validator"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotModifySuperAdmin = errors.New("cannot modify a superadmin account")
+	ErrCannotModifySelf       = errors.New("cannot perform this action on your own account")
+	ErrUserNotDeleted         = errors.New("user is not deleted")
+)
+
+// UpdateUserInput chứa các trường có thể cập nhật; nil nghĩa là giữ nguyên
+type UpdateUserInput struct {
+	Email    *string
+	Username *string
+	Role     *models.Role
+	Active   *bool
+	FullName *string
+	Phone    *string
+	Address  *string
+}
+
+// ListUsers liệt kê user theo bộ lọc và phân trang
+func (s *Service) ListUsers(filter repository.UserFilter, params pagination.Params) ([]models.User, int64, error) {
+	return s.repos.Users.List(filter, params)
+}
+
+// GetUser lấy user theo ID, có thể bao gồm user đã bị xóa mềm
+func (s *Service) GetUser(id uint, includeDeleted bool) (*models.User, error) {
+	find := s.repos.Users.FindByID
+	if includeDeleted {
+		find = s.repos.Users.FindByIDUnscoped
+	}
+
+	user, err := find(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// normalizeEmail chuẩn hóa email trước khi lưu hoặc tra cứu: bỏ khoảng trắng và chuyển
+// về chữ thường, để cùng một địa chỉ gõ khác hoa thường vẫn là một tài khoản
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// CreateUser lưu user mới vào database
+func (s *Service) CreateUser(user *models.User) error {
+	user.Email = normalizeEmail(user.Email)
+	return s.repos.Users.Create(user)
+}
+
+// SaveUser lưu các thay đổi của user và vô hiệu hóa cache của user
+func (s *Service) SaveUser(user *models.User) error {
+	if err := s.repos.Users.Save(user); err != nil {
+		return err
+	}
+	s.invalidateUserCache(user.ID)
+	return nil
+}
+
+// UpdateUser cập nhật thông tin user và trả về danh sách trường đã thay đổi
+func (s *Service) UpdateUser(actorID, id uint, input UpdateUserInput) (*models.User, map[string]models.FieldChange, error) {
+	user, err := s.GetUser(id, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Không cho phép sửa SuperAdmin hoặc nâng quyền lên SuperAdmin
+	if user.Role == models.RoleSuperAdmin {
+		return nil, nil, ErrCannotModifySuperAdmin
+	}
+	if input.Role != nil && *input.Role == models.RoleSuperAdmin {
+		return nil, nil, ErrCannotModifySuperAdmin
+	}
+	if user.ID == actorID && (input.Role != nil || input.Active != nil) {
+		return nil, nil, ErrCannotModifySelf
+	}
+
+	before := userSnapshot(user)
+
+	if input.Email != nil {
+		email := normalizeEmail(*input.Email)
+		if !validator.IsValidEmail(email) {
+			return nil, nil, ErrInvalidEmail
+		}
+		if email != user.Email && s.emailTaken(email, user.ID) {
+			return nil, nil, ErrEmailTaken
+		}
+		user.Email = email
+	}
+	if input.Username != nil {
+		username := strings.TrimSpace(*input.Username)
+		if !isValidUsername(username) {
+			return nil, nil, ErrInvalidUsername
+		}
+		if username != user.Username && s.usernameTaken(username, user.ID) {
+			return nil, nil, ErrUsernameTaken
+		}
+		user.Username = username
+	}
+	if input.Role != nil {
+		if !s.RoleExists(*input.Role) {
+			return nil, nil, ErrRoleNotFound
+		}
+		user.Role = *input.Role
+	}
+	if input.Active != nil {
+		user.Active = *input.Active
+	}
+	if input.FullName != nil {
+		user.Profile.FullName = *input.FullName
+	}
+	if input.Phone != nil {
+		user.Profile.Phone = *input.Phone
+	}
+	if input.Address != nil {
+		user.Profile.Address = *input.Address
+	}
+
+	changes := diffSnapshots(before, userSnapshot(user))
+	if len(changes) == 0 {
+		return user, changes, nil
+	}
+
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		if err := tx.Users.Save(user); err != nil {
+			return err
+		}
+		user.Profile.UserID = user.ID
+		return tx.Users.SaveProfile(&user.Profile)
+	})
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			if strings.Contains(err.Error(), "username") {
+				return nil, nil, ErrUsernameTaken
+			}
+			return nil, nil, ErrEmailTaken
+		}
+		return nil, nil, err
+	}
+
+	// Role hoặc trạng thái thay đổi: token hiện tại mang thông tin cũ nên phải thu hồi
+	if _, ok := changes["role"]; ok || !user.Active {
+		if _, err := s.RevokeAllSessions(user.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	s.invalidateUserCache(user.ID)
+	return user, changes, nil
+}
+
+// DeleteUser xóa mềm user và thu hồi mọi phiên đăng nhập
+func (s *Service) DeleteUser(actorID, id uint) (*models.User, error) {
+	user, err := s.GetUser(id, false)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == models.RoleSuperAdmin {
+		return nil, ErrCannotModifySuperAdmin
+	}
+	if user.ID == actorID {
+		return nil, ErrCannotModifySelf
+	}
+
+	if err := s.repos.Users.Delete(user.ID); err != nil {
+		return nil, err
+	}
+	if _, err := s.RevokeAllSessions(user.ID); err != nil {
+		return nil, err
+	}
+
+	s.invalidateUserCache(user.ID)
+	return user, nil
+}
+
+// RestoreUser khôi phục user đã bị xóa mềm
+func (s *Service) RestoreUser(id uint) (*models.User, error) {
+	user, err := s.GetUser(id, true)
+	if err != nil {
+		return nil, err
+	}
+	if !user.DeletedAt.Valid {
+		return nil, ErrUserNotDeleted
+	}
+
+	if err := s.repos.Users.Restore(user.ID); err != nil {
+		return nil, err
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	s.invalidateUserCache(user.ID)
+	return user, nil
+}
+
+// emailTaken kiểm tra email đã được dùng bởi user khác chưa
+func (s *Service) emailTaken(email string, excludeID uint) bool {
+	exists, err := s.repos.Users.EmailExists(email, excludeID)
+	return err == nil && exists
+}
+
+// usernameTaken kiểm tra username đã được dùng bởi user khác chưa
+func (s *Service) usernameTaken(username string, excludeID uint) bool {
+	exists, err := s.repos.Users.UsernameExists(username, excludeID)
+	return err == nil && exists
+}
+
+// userSnapshot lấy các trường có thể chỉnh sửa để so sánh trước/sau
+func userSnapshot(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"email":     user.Email,
+		"username":  user.Username,
+		"role":      user.Role,
+		"active":    user.Active,
+		"full_name": user.Profile.FullName,
+		"phone":     user.Profile.Phone,
+		"address":   user.Profile.Address,
+	}
+}
+
+// diffSnapshots trả về các trường có giá trị khác nhau giữa hai snapshot
+func diffSnapshots(before, after map[string]interface{}) map[string]models.FieldChange {
+	changes := make(map[string]models.FieldChange)
+	for key, oldValue := range before {
+		if newValue := after[key]; newValue != oldValue {
+			changes[key] = models.FieldChange{Before: oldValue, After: newValue}
+		}
+	}
+	return changes
+}
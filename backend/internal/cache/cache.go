@@ -1,117 +1,63 @@
 package cache
 
 import (
-    "sync"
+    "fmt"
     "time"
 )
 
-// Item đại diện cho một mục trong cache
-type Item struct {
-    Value      interface{}
-    Expiration int64
-}
-
-// Cache là một bộ nhớ đệm đơn giản
-type Cache struct {
-    items map[string]Item
-    mu    sync.RWMutex
-}
-
-// NewCache tạo một cache mới
-func NewCache() *Cache {
-    cache := &Cache{
-        items: make(map[string]Item),
-    }
-    
-    // Khởi động goroutine dọn dẹp các mục hết hạn
-    go cache.janitor()
-    
-    return cache
-}
-
-// Set thêm một mục vào cache với thời gian hết hạn
-func (c *Cache) Set(key string, value interface{}, duration time.Duration) {
-    c.mu.Lock()
-    defer c.mu.Unlock()
-    
-    var expiration int64
-    if duration > 0 {
-        expiration = time.Now().Add(duration).UnixNano()
-    }
-    
-    c.items[key] = Item{
-        Value:      value,
-        Expiration: expiration,
-    }
-}
-
-// Get lấy một mục từ cache
-func (c *Cache) Get(key string) (interface{}, bool) {
-    c.mu.RLock()
-    defer c.mu.RUnlock()
-    
-    item, found := c.items[key]
-    if !found {
-        return nil, false
-    }
-    
-    // Kiểm tra hết hạn
-    if item.Expiration > 0 && time.Now().UnixNano() > item.Expiration {
-        return nil, false
-    }
-    
-    return item.Value, true
-}
-
-// Delete xóa một mục khỏi cache
-func (c *Cache) Delete(key string) {
-    c.mu.Lock()
-    defer c.mu.Unlock()
-    
-    delete(c.items, key)
-}
-
-// Clear xóa tất cả các mục trong cache
-func (c *Cache) Clear() {
-    c.mu.Lock()
-    defer c.mu.Unlock()
-    
-    c.items = make(map[string]Item)
-}
+// Các backend cache được hỗ trợ
+const (
+    BackendMemory = "memory"
+    BackendRedis  = "redis"
+)
 
-// janitor dọn dẹp các mục hết hạn
-func (c *Cache) janitor() {
-    ticker := time.NewTicker(time.Minute)
-    defer ticker.Stop()
-    
-    for range ticker.C {
-        c.mu.Lock()
-        now := time.Now().UnixNano()
-        for key, item := range c.items {
-            if item.Expiration > 0 && now > item.Expiration {
-                delete(c.items, key)
-            }
-        }
-        c.mu.Unlock()
+// Cache là bộ nhớ đệm key/value có thời hạn. Giá trị được mã hóa JSON khi lưu và giải mã
+// vào dest khi đọc, nên mọi backend có cùng ngữ nghĩa và không chia sẻ object giữa các lần đọc
+type Cache interface {
+    // Get giải mã giá trị của key vào dest, trả về false nếu không có hoặc đã hết hạn
+    Get(key string, dest interface{}) bool
+    // Set lưu value với thời hạn ttl; ttl <= 0 là không hết hạn
+    Set(key string, value interface{}, ttl time.Duration)
+    // Delete xóa các key
+    Delete(keys ...string)
+    // Clear xóa mọi key của cache này
+    Clear()
+    // Stats trả về số liệu thống kê từ khi khởi tạo
+    Stats() Stats
+}
+
+// Stats là số liệu thống kê của cache
+type Stats struct {
+    Backend   string `json:"backend"`
+    Hits      int64  `json:"hits"`
+    Misses    int64  `json:"misses"`
+    Sets      int64  `json:"sets"`
+    Deletes   int64  `json:"deletes"`
+    Evictions int64  `json:"evictions"`
+    // Entries là số mục hiện có; chỉ backend memory theo dõi được
+    Entries int64 `json:"entries"`
+}
+
+// Config là cấu hình chọn và khởi tạo backend cache
+type Config struct {
+    Backend string
+    // MaxEntries là số mục tối đa của backend memory trước khi loại bỏ mục ít dùng nhất, 0 là không giới hạn
+    MaxEntries int
+    // Cấu hình Redis (hoặc server tương thích giao thức Redis)
+    RedisAddr     string
+    RedisPassword string
+    RedisDB       int
+    // Prefix được thêm vào trước mọi key trong Redis để dùng chung database với ứng dụng khác
+    Prefix string
+}
+
+// New tạo cache theo backend trong cấu hình
+func New(config Config) (Cache, error) {
+    switch config.Backend {
+    case BackendMemory, "":
+        return NewMemoryCache(config.MaxEntries), nil
+    case BackendRedis:
+        return NewRedisCache(config.RedisAddr, config.RedisPassword, config.RedisDB, config.Prefix)
     }
+    return nil, fmt.Errorf("unsupported cache backend: %q", config.Backend)
 }
-
-// Biến cache toàn cục
-var DefaultCache = NewCache()
-
-// Các hàm cache toàn cục
-func Set(key string, value interface{}, duration time.Duration) {
-    DefaultCache.Set(key, value, duration)
-}
-
-func Get(key string) (interface{}, bool) {
-    return DefaultCache.Get(key)
-}
-
-func Delete(key string) {
-    DefaultCache.Delete(key)
-}
-
-func Clear() {
-    DefaultCache.Clear()
-}
\ No newline at end of file
@@ -0,0 +1,206 @@
+package cache
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
+    "math"
+    mathrand "math/rand"
+    "sync"
+    "time"
+)
+
+// tagKeyPrefix là prefix của key lưu version hiện tại của mỗi tag
+const tagKeyPrefix = "tag:"
+
+// Options điều khiển cách GetOrLoad cache một giá trị
+type Options struct {
+    // TTL là thời gian giá trị được coi là mới, <= 0 là không hết hạn
+    TTL time.Duration
+    // Jitter là tỉ lệ dao động ngẫu nhiên của TTL (ví dụ 0.1 là ±10%), để các key được
+    // tạo cùng lúc không hết hạn cùng lúc
+    Jitter float64
+    // StaleTTL là thời gian sau TTL mà giá trị cũ vẫn được trả về ngay trong khi một
+    // goroutine nền tải lại giá trị mới (stale-while-revalidate). 0 là tắt
+    StaleTTL time.Duration
+    // Tags gắn với giá trị; InvalidateTags với một trong các tag làm giá trị hết hiệu lực
+    Tags []string
+}
+
+// LoadFunc tải giá trị từ nguồn gốc (database...) khi cache không có
+type LoadFunc func(ctx context.Context) (interface{}, error)
+
+// entry là dạng lưu trong cache của giá trị do Loader quản lý
+type entry struct {
+    Value      json.RawMessage   `json:"v"`
+    FreshUntil int64             `json:"f"`
+    Tags       map[string]string `json:"t,omitempty"`
+}
+
+// Loader cài đặt cache-aside trên một Cache bất kỳ: gộp các lần tải đồng thời của cùng
+// key (singleflight), TTL có jitter, stale-while-revalidate và vô hiệu hóa theo tag.
+//
+// Tag được cài đặt bằng version: mỗi tag có một version ngẫu nhiên lưu trong cache, entry
+// ghi lại version của các tag lúc tải và bị coi là miss khi version đã đổi. Cách này đúng
+// với mọi backend và an toàn khi key của tag bị loại bỏ (entry cũ không bao giờ khớp lại)
+type Loader struct {
+    cache Cache
+    // RefreshTimeout giới hạn thời gian tải lại nền của stale-while-revalidate
+    RefreshTimeout time.Duration
+
+    mu      sync.Mutex
+    flights map[string]*flight
+}
+
+// flight là một lần tải đang chạy, các caller cùng key chờ và dùng chung kết quả
+type flight struct {
+    done chan struct{}
+    data []byte
+    err  error
+}
+
+// NewLoader tạo Loader trên cache
+func NewLoader(c Cache) *Loader {
+    return &Loader{
+        cache:          c,
+        RefreshTimeout: 10 * time.Second,
+        flights:        make(map[string]*flight),
+    }
+}
+
+// Cache trả về cache bên dưới
+func (l *Loader) Cache() Cache {
+    return l.cache
+}
+
+// GetOrLoad giải mã giá trị của key vào dest. Nếu cache không có (hoặc tag đã bị vô hiệu
+// hóa), load được gọi đúng một lần cho mọi caller đồng thời và kết quả được cache lại.
+// Giá trị quá TTL nhưng còn trong StaleTTL được trả về ngay và tải lại ở nền.
+// Lỗi của load được trả về nguyên vẹn và không được cache
+func (l *Loader) GetOrLoad(ctx context.Context, key string, dest interface{}, opts Options, load LoadFunc) error {
+    var cached entry
+    if l.cache.Get(key, &cached) && l.tagsValid(cached.Tags) {
+        if time.Now().UnixNano() > cached.FreshUntil {
+            go l.refresh(key, opts, load)
+        }
+        return json.Unmarshal(cached.Value, dest)
+    }
+
+    data, err := l.do(ctx, key, opts, load)
+    if err != nil {
+        return err
+    }
+    return json.Unmarshal(data, dest)
+}
+
+// InvalidateTags làm mọi giá trị gắn với một trong các tag hết hiệu lực
+func (l *Loader) InvalidateTags(tags ...string) {
+    for _, tag := range tags {
+        l.cache.Set(tagKeyPrefix+tag, newTagVersion(), 0)
+    }
+}
+
+// refresh tải lại key ở nền với context riêng vì request gốc có thể đã kết thúc
+func (l *Loader) refresh(key string, opts Options, load LoadFunc) {
+    ctx, cancel := context.WithTimeout(context.Background(), l.RefreshTimeout)
+    defer cancel()
+    l.do(ctx, key, opts, load)
+}
+
+// do chạy load cho key, hoặc chờ lần tải đang chạy của key đó
+func (l *Loader) do(ctx context.Context, key string, opts Options, load LoadFunc) ([]byte, error) {
+    l.mu.Lock()
+    if f, ok := l.flights[key]; ok {
+        l.mu.Unlock()
+        select {
+        case <-f.done:
+            return f.data, f.err
+        case <-ctx.Done():
+            return nil, ctx.Err()
+        }
+    }
+    f := &flight{done: make(chan struct{})}
+    l.flights[key] = f
+    l.mu.Unlock()
+
+    f.data, f.err = l.load(ctx, key, opts, load)
+
+    l.mu.Lock()
+    delete(l.flights, key)
+    l.mu.Unlock()
+    close(f.done)
+
+    return f.data, f.err
+}
+
+// load gọi load và lưu kết quả. Version của tag được đọc trước khi tải nên nếu tag bị
+// vô hiệu hóa trong lúc tải, entry vừa lưu cũng bị coi là cũ
+func (l *Loader) load(ctx context.Context, key string, opts Options, load LoadFunc) ([]byte, error) {
+    tags := l.tagVersions(opts.Tags)
+
+    value, err := load(ctx)
+    if err != nil {
+        return nil, err
+    }
+    data, err := json.Marshal(value)
+    if err != nil {
+        return nil, err
+    }
+
+    // TTL <= 0 là không hết hạn, khi đó giá trị không bao giờ bị coi là cũ
+    ttl := jitter(opts.TTL, opts.Jitter)
+    freshUntil := int64(math.MaxInt64)
+    if ttl > 0 {
+        freshUntil = time.Now().Add(ttl).UnixNano()
+    }
+    l.cache.Set(key, entry{Value: data, FreshUntil: freshUntil, Tags: tags}, ttl+opts.StaleTTL)
+
+    return data, nil
+}
+
+// tagVersions trả về version hiện tại của các tag, tạo version mới cho tag chưa có
+func (l *Loader) tagVersions(tags []string) map[string]string {
+    if len(tags) == 0 {
+        return nil
+    }
+
+    versions := make(map[string]string, len(tags))
+    for _, tag := range tags {
+        var version string
+        if !l.cache.Get(tagKeyPrefix+tag, &version) {
+            version = newTagVersion()
+            l.cache.Set(tagKeyPrefix+tag, version, 0)
+        }
+        versions[tag] = version
+    }
+    return versions
+}
+
+// tagsValid kiểm tra version của các tag trong entry vẫn là version hiện tại
+func (l *Loader) tagsValid(tags map[string]string) bool {
+    for tag, version := range tags {
+        var current string
+        if !l.cache.Get(tagKeyPrefix+tag, &current) || current != version {
+            return false
+        }
+    }
+    return true
+}
+
+// newTagVersion sinh version ngẫu nhiên cho tag
+func newTagVersion() string {
+    b := make([]byte, 8)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
+
+// jitter trả về ttl dao động ngẫu nhiên trong khoảng ±fraction
+func jitter(ttl time.Duration, fraction float64) time.Duration {
+    if ttl <= 0 || fraction <= 0 {
+        return ttl
+    }
+    fraction = math.Min(fraction, 1)
+    delta := (mathrand.Float64()*2 - 1) * fraction * float64(ttl)
+    return ttl + time.Duration(delta)
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+    "container/list"
+    "context"
+    "encoding/json"
+    "sync"
+    "time"
+
+    "github.com/yourusername/tastygo/internal/metrics"
+)
+
+// memoryItem là một mục trong MemoryCache
+type memoryItem struct {
+    key        string
+    value      []byte
+    expiration int64
+}
+
+// MemoryCache là cache trong bộ nhớ có giới hạn số mục, loại bỏ mục ít dùng nhất (LRU) khi đầy
+type MemoryCache struct {
+    mu         sync.Mutex
+    items      map[string]*list.Element
+    order      *list.List // đầu danh sách là mục mới dùng nhất
+    maxEntries int
+    stats      Stats
+}
+
+// NewMemoryCache tạo cache trong bộ nhớ với tối đa maxEntries mục (0 là không giới hạn).
+// Mục hết hạn không được trả về bởi Get, nhưng chỉ được giải phóng khi bị đọc, bị loại bỏ
+// hoặc khi Janitor chạy
+func NewMemoryCache(maxEntries int) *MemoryCache {
+    return &MemoryCache{
+        items:      make(map[string]*list.Element),
+        order:      list.New(),
+        maxEntries: maxEntries,
+        stats:      Stats{Backend: BackendMemory},
+    }
+}
+
+// Set thêm một mục vào cache với thời gian hết hạn
+func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+    data, err := json.Marshal(value)
+    if err != nil {
+        return
+    }
+
+    var expiration int64
+    if ttl > 0 {
+        expiration = time.Now().Add(ttl).UnixNano()
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.stats.Sets++
+    if element, found := c.items[key]; found {
+        item := element.Value.(*memoryItem)
+        item.value = data
+        item.expiration = expiration
+        c.order.MoveToFront(element)
+        return
+    }
+
+    c.items[key] = c.order.PushFront(&memoryItem{key: key, value: data, expiration: expiration})
+    for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+        c.removeElement(c.order.Back())
+        c.stats.Evictions++
+    }
+}
+
+// Get lấy một mục từ cache
+func (c *MemoryCache) Get(key string, dest interface{}) bool {
+    c.mu.Lock()
+    element, found := c.items[key]
+    var data []byte
+    if found {
+        item := element.Value.(*memoryItem)
+        if item.expiration > 0 && time.Now().UnixNano() > item.expiration {
+            c.removeElement(element)
+            found = false
+        } else {
+            data = item.value
+            c.order.MoveToFront(element)
+        }
+    }
+    if found {
+        c.stats.Hits++
+    } else {
+        c.stats.Misses++
+    }
+    c.mu.Unlock()
+
+    if !found || json.Unmarshal(data, dest) != nil {
+        metrics.CacheRequests.WithLabelValues("miss").Inc()
+        return false
+    }
+    metrics.CacheRequests.WithLabelValues("hit").Inc()
+    return true
+}
+
+// Delete xóa các mục khỏi cache
+func (c *MemoryCache) Delete(keys ...string) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    for _, key := range keys {
+        if element, found := c.items[key]; found {
+            c.removeElement(element)
+            c.stats.Deletes++
+        }
+    }
+}
+
+// Clear xóa tất cả các mục trong cache
+func (c *MemoryCache) Clear() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.items = make(map[string]*list.Element)
+    c.order.Init()
+}
+
+// Stats trả về số liệu thống kê của cache
+func (c *MemoryCache) Stats() Stats {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    stats := c.stats
+    stats.Entries = int64(c.order.Len())
+    return stats
+}
+
+// Janitor định kỳ dọn dẹp các mục hết hạn cho tới khi ctx bị hủy
+func (c *MemoryCache) Janitor(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            c.deleteExpired()
+        }
+    }
+}
+
+// deleteExpired xóa các mục đã hết hạn
+func (c *MemoryCache) deleteExpired() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    now := time.Now().UnixNano()
+    for _, element := range c.items {
+        item := element.Value.(*memoryItem)
+        if item.expiration > 0 && now > item.expiration {
+            c.removeElement(element)
+        }
+    }
+}
+
+// removeElement xóa một phần tử khỏi map và danh sách LRU, caller phải giữ lock
+func (c *MemoryCache) removeElement(element *list.Element) {
+    c.order.Remove(element)
+    delete(c.items, element.Value.(*memoryItem).key)
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+    "context"
+    "encoding/json"
+    "sync/atomic"
+    "time"
+
+    "github.com/redis/go-redis/v9"
+    "github.com/yourusername/tastygo/internal/metrics"
+)
+
+// redisTimeout là thời gian tối đa cho một thao tác cache; cache lỗi được coi như miss
+// thay vì làm chậm request
+const redisTimeout = 500 * time.Millisecond
+
+// RedisCache là cache dùng server tương thích giao thức Redis, chia sẻ được giữa nhiều instance
+type RedisCache struct {
+    client *redis.Client
+    prefix string
+
+    hits    atomic.Int64
+    misses  atomic.Int64
+    sets    atomic.Int64
+    deletes atomic.Int64
+}
+
+// NewRedisCache kết nối tới Redis tại addr. Mọi key được thêm prefix
+func NewRedisCache(addr, password string, db int, prefix string) (*RedisCache, error) {
+    client := redis.NewClient(&redis.Options{
+        Addr:     addr,
+        Password: password,
+        DB:       db,
+    })
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    if err := client.Ping(ctx).Err(); err != nil {
+        client.Close()
+        return nil, err
+    }
+
+    return &RedisCache{client: client, prefix: prefix}, nil
+}
+
+// Set lưu value với thời gian hết hạn
+func (c *RedisCache) Set(key string, value interface{}, ttl time.Duration) {
+    data, err := json.Marshal(value)
+    if err != nil {
+        return
+    }
+    if ttl < 0 {
+        ttl = 0
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+    defer cancel()
+    if c.client.Set(ctx, c.prefix+key, data, ttl).Err() == nil {
+        c.sets.Add(1)
+    }
+}
+
+// Get lấy value của key và giải mã vào dest
+func (c *RedisCache) Get(key string, dest interface{}) bool {
+    ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+    defer cancel()
+
+    data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+    if err != nil || json.Unmarshal(data, dest) != nil {
+        c.misses.Add(1)
+        metrics.CacheRequests.WithLabelValues("miss").Inc()
+        return false
+    }
+    c.hits.Add(1)
+    metrics.CacheRequests.WithLabelValues("hit").Inc()
+    return true
+}
+
+// Delete xóa các key
+func (c *RedisCache) Delete(keys ...string) {
+    if len(keys) == 0 {
+        return
+    }
+    prefixed := make([]string, len(keys))
+    for i, key := range keys {
+        prefixed[i] = c.prefix + key
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+    defer cancel()
+    if deleted, err := c.client.Del(ctx, prefixed...).Result(); err == nil {
+        c.deletes.Add(deleted)
+    }
+}
+
+// Clear xóa mọi key có prefix của cache này, không ảnh hưởng key khác trong cùng database
+func (c *RedisCache) Clear() {
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+
+    iter := c.client.Scan(ctx, 0, c.prefix+"*", 100).Iterator()
+    var batch []string
+    for iter.Next(ctx) {
+        batch = append(batch, iter.Val())
+        if len(batch) == 100 {
+            c.client.Del(ctx, batch...)
+            batch = batch[:0]
+        }
+    }
+    if len(batch) > 0 {
+        c.client.Del(ctx, batch...)
+    }
+}
+
+// Stats trả về số liệu thống kê của instance này. Evictions và Entries do Redis quản lý nên luôn là 0
+func (c *RedisCache) Stats() Stats {
+    return Stats{
+        Backend: BackendRedis,
+        Hits:    c.hits.Load(),
+        Misses:  c.misses.Load(),
+        Sets:    c.sets.Load(),
+        Deletes: c.deletes.Load(),
+    }
+}
+
+// Name trả về tên dùng trong báo cáo /readyz
+func (c *RedisCache) Name() string {
+    return "cache"
+}
+
+// Check ping Redis
+func (c *RedisCache) Check(ctx context.Context) error {
+    return c.client.Ping(ctx).Err()
+}
+
+// Close đóng kết nối tới Redis
+func (c *RedisCache) Close() error {
+    return c.client.Close()
+}
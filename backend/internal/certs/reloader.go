@@ -0,0 +1,159 @@
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/logging"
+)
+
+// Reloader giữ chứng chỉ server (và CA của client khi bật mutual TLS) đọc từ file, nạp lại
+// khi nội dung file thay đổi mà không cần khởi động lại server. Kết nối đang mở giữ chứng chỉ
+// cũ, chỉ handshake mới dùng chứng chỉ mới
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	minVersion   uint16
+
+	mu       sync.RWMutex
+	config   *tls.Config
+	leaf     *x509.Certificate
+	checksum []byte
+}
+
+// NewReloader đọc chứng chỉ lần đầu; clientCAFile rỗng là không yêu cầu chứng chỉ client
+func NewReloader(certFile, keyFile, clientCAFile string, minVersion uint16) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		minVersion:   minVersion,
+	}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig trả về cấu hình cho http.Server. Mỗi handshake lấy cấu hình mới nhất
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.config, nil
+		},
+	}
+}
+
+// NotAfter trả về thời điểm hết hạn của chứng chỉ đang dùng
+func (r *Reloader) NotAfter() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.leaf.NotAfter
+}
+
+// Reload đọc lại các file và thay chứng chỉ nếu nội dung thay đổi. File lỗi (ví dụ đang
+// được ghi dở) không làm mất chứng chỉ đang dùng
+func (r *Reloader) Reload() (bool, error) {
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+	var caPEM []byte
+	if r.clientCAFile != "" {
+		if caPEM, err = os.ReadFile(r.clientCAFile); err != nil {
+			return false, err
+		}
+	}
+
+	hash := sha256.New()
+	for _, data := range [][]byte{certPEM, keyPEM, caPEM} {
+		hash.Write(data)
+		hash.Write([]byte{0})
+	}
+	checksum := hash.Sum(nil)
+
+	r.mu.RLock()
+	unchanged := bytes.Equal(checksum, r.checksum)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("load key pair: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return false, fmt.Errorf("parse certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		MinVersion:   r.minVersion,
+		Certificates: []tls.Certificate{cert},
+		// Cấu hình trả về từ GetConfigForClient thay thế hoàn toàn cấu hình của server
+		// nên phải khai báo lại ALPN cho HTTP/2
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	if caPEM != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return false, errors.New("client CA file contains no certificates")
+		}
+		// Route công khai vẫn truy cập được không cần chứng chỉ client; route nội bộ kiểm tra
+		// chứng chỉ đã được xác minh qua middleware
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	r.mu.Lock()
+	r.config = config
+	r.leaf = leaf
+	r.checksum = checksum
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Watch kiểm tra file theo chu kỳ interval và nạp lại khi thay đổi, cho tới khi ctx bị hủy
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				logging.Error("Failed to reload TLS certificate, keeping the current one", map[string]interface{}{
+					"error":     err.Error(),
+					"cert_file": r.certFile,
+				})
+				continue
+			}
+			if reloaded {
+				logging.Info("TLS certificate reloaded", map[string]interface{}{
+					"cert_file": r.certFile,
+					"not_after": r.NotAfter(),
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnyOrigin cho phép mọi origin. Không dùng được cùng AllowCredentials
+const AnyOrigin = "*"
+
+// Policy là chính sách CORS cho một nhóm route
+type Policy struct {
+	// AllowedOrigins là origin được phép, dạng "scheme://host[:port]". Dấu "*" trong origin
+	// khớp một hoặc nhiều nhãn tên miền, ví dụ "https://*.tastygo.vn" hoặc "http://localhost:*";
+	// "*" đứng riêng là mọi origin
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge là thời gian trình duyệt được cache kết quả preflight; 0 là không gửi header
+	MaxAge time.Duration
+}
+
+// Route ghi đè Policy cho các path bắt đầu bằng Prefix (theo từng đoạn path,
+// "/api/public" khớp "/api/public/menu" nhưng không khớp "/api/publicity")
+type Route struct {
+	Prefix string
+	Policy Policy
+}
+
+// Config là chính sách mặc định cùng các ghi đè theo route. Route có prefix dài nhất thắng
+type Config struct {
+	Default Policy
+	Routes  []Route
+}
+
+// CheckOrigin kiểm tra một origin (hoặc pattern) có đúng dạng "scheme://host[:port]"
+func CheckOrigin(origin string) error {
+	if origin == AnyOrigin {
+		return nil
+	}
+	u, err := url.Parse(strings.ReplaceAll(origin, "*", "0"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("invalid origin %q, expected scheme://host[:port]", origin)
+	}
+	return nil
+}
+
+// compiledPolicy là Policy đã chuẩn hóa để so khớp nhanh khi xử lý request
+type compiledPolicy struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	patterns    []*regexp.Regexp
+	methods     map[string]bool
+	headers     map[string]bool
+	credentials bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// wildcardLabels thay cho "*" trong pattern: một hoặc nhiều nhãn tên miền (hoặc số port)
+const wildcardLabels = `[a-z0-9-]+(\.[a-z0-9-]+)*`
+
+func compile(p Policy) *compiledPolicy {
+	c := &compiledPolicy{
+		origins: make(map[string]bool),
+		methods: make(map[string]bool),
+		headers: make(map[string]bool),
+	}
+	for _, origin := range p.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch {
+		case origin == AnyOrigin:
+			c.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			expr := strings.ReplaceAll(regexp.QuoteMeta(origin), `\*`, wildcardLabels)
+			c.patterns = append(c.patterns, regexp.MustCompile("^"+expr+"$"))
+		default:
+			c.origins[origin] = true
+		}
+	}
+
+	methods := make([]string, 0, len(p.AllowedMethods))
+	for _, method := range p.AllowedMethods {
+		method = strings.ToUpper(method)
+		c.methods[method] = true
+		methods = append(methods, method)
+	}
+	for _, header := range p.AllowedHeaders {
+		c.headers[strings.ToLower(header)] = true
+	}
+
+	// Trình duyệt từ chối "*" kèm credentials nên không bao giờ bật credentials khi cho mọi origin
+	c.credentials = p.AllowCredentials && !c.anyOrigin
+	c.allowMethods = strings.Join(methods, ", ")
+	c.allowHeaders = strings.Join(p.AllowedHeaders, ", ")
+	c.exposeHeaders = strings.Join(p.ExposedHeaders, ", ")
+	if p.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(p.MaxAge.Seconds()))
+	}
+	return c
+}
+
+// allowOrigin cho biết origin có được phép không
+func (c *compiledPolicy) allowOrigin(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if c.origins[origin] {
+		return true
+	}
+	for _, pattern := range c.patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowRequestHeaders cho biết mọi header trong Access-Control-Request-Headers có được phép không
+func (c *compiledPolicy) allowRequestHeaders(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.ToLower(strings.TrimSpace(header))
+		if header != "" && !c.headers[header] {
+			return false
+		}
+	}
+	return true
+}
+
+// route là Route đã compile
+type route struct {
+	prefix string
+	policy *compiledPolicy
+}
+
+// Middleware trả về middleware xử lý CORS theo cfg. Request có origin không được phép
+// vẫn được xử lý nhưng không có header CORS, nên trình duyệt không cho đọc response;
+// preflight từ origin không được phép hoặc xin method/header không được phép bị trả 403
+func Middleware(cfg Config) gin.HandlerFunc {
+	defaultPolicy := compile(cfg.Default)
+	routes := make([]route, 0, len(cfg.Routes))
+	for _, r := range cfg.Routes {
+		routes = append(routes, route{prefix: strings.TrimSuffix(r.Prefix, "/"), policy: compile(r.Policy)})
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].prefix) > len(routes[j].prefix)
+	})
+
+	return func(c *gin.Context) {
+		policy := defaultPolicy
+		path := c.Request.URL.Path
+		for _, r := range routes {
+			if path == r.prefix || strings.HasPrefix(path, r.prefix+"/") {
+				policy = r.policy
+				break
+			}
+		}
+
+		header := c.Writer.Header()
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		// Response phụ thuộc vào Origin (và các header preflight) nên cache trung gian
+		// phải phân biệt theo các header này
+		header.Add("Vary", "Origin")
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		allowed := policy.allowOrigin(origin)
+		if allowed && preflight {
+			method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+			allowed = policy.methods[method] && policy.allowRequestHeaders(c.GetHeader("Access-Control-Request-Headers"))
+		}
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if policy.anyOrigin && !policy.credentials {
+			header.Set("Access-Control-Allow-Origin", AnyOrigin)
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if policy.credentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			header.Set("Access-Control-Allow-Methods", policy.allowMethods)
+			if policy.allowHeaders != "" {
+				header.Set("Access-Control-Allow-Headers", policy.allowHeaders)
+			}
+			if policy.maxAge != "" {
+				header.Set("Access-Control-Max-Age", policy.maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if policy.exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", policy.exposeHeaders)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/metrics"
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/migrations"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Các database driver được hỗ trợ
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Drivers là danh sách driver được hỗ trợ, mỗi driver có bộ migration riêng
+var Drivers = []string{DriverSQLite, DriverPostgres}
+
+// Config chứa thông tin kết nối và cấu hình connection pool
+type Config struct {
+	// Driver là "sqlite" hoặc "postgres"
+	Driver string
+	// DSN là đường dẫn file với SQLite hoặc connection string với PostgreSQL
+	DSN string
+	// Các giá trị <= 0 giữ mặc định của database/sql
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// LogLevel là mức log của GORM: "silent", "error", "warn" (mặc định) hoặc "info"
+	LogLevel string
+	// Production bật chế độ production: không tự migrate và sinh mật khẩu ngẫu nhiên cho superadmin mặc định
+	Production bool
+}
+
+// Open mở kết nối theo driver và áp dụng cấu hình pool, không migrate hay seed dữ liệu
+func Open(config Config) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch config.Driver {
+	case DriverSQLite, "":
+		dialector = sqlite.Open(config.DSN)
+	case DriverPostgres:
+		dialector = postgres.Open(config.DSN)
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %q", config.Driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: newGormLogger(config.LogLevel),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ghi nhận thời gian truy vấn cho Prometheus
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+	if config.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	}
+
+	return db, nil
+}
+
+// MigrationFS trả về bộ migration dành cho driver
+func MigrationFS(driver string) (fs.FS, error) {
+	if driver == "" {
+		driver = DriverSQLite
+	}
+	for _, supported := range Drivers {
+		if driver == supported {
+			return fs.Sub(migrations.FS, driver)
+		}
+	}
+	return nil, fmt.Errorf("unsupported database driver: %q", driver)
+}
+
+// InitDB mở kết nối, áp dụng migration và tạo dữ liệu mặc định.
+// Kết nối được trả về để truyền vào các repository thay vì dùng biến toàn cục.
+func InitDB(config Config) (*gorm.DB, error) {
+	db, err := Open(config)
+	if err != nil {
+		return nil, err
+	}
+	
+	isProduction := config.Production
+	
+	// Áp dụng migration. Ở chế độ release server không tự migrate mà từ chối khởi động,
+	// migration phải được chạy trước bằng `tastygo migrate up`.
+	if err := migrateSchema(db, config.Driver, isProduction); err != nil {
+		return nil, err
+	}
+	
+	// Khởi tạo các role hệ thống và quyền mặc định
+	if err := seedRoles(db); err != nil {
+		return nil, err
+	}
+	
+	// Check if superadmin exists, if not create one
+	var count int64
+	db.Model(&models.User{}).Where("role = ?", models.RoleSuperAdmin).Count(&count)
+	
+	if count == 0 {
+		// Tạo mật khẩu ngẫu nhiên nếu không phải môi trường development
+		defaultPassword := "admin123"
+		
+		if isProduction {
+			// Tạo mật khẩu ngẫu nhiên cho môi trường production
+			randomBytes := make([]byte, 12)
+			if _, err := rand.Read(randomBytes); err == nil {
+				defaultPassword = base64.URLEncoding.EncodeToString(randomBytes)
+				log.Printf("Generated random password for superadmin: %s", defaultPassword)
+				log.Println("IMPORTANT: Save this password securely. It will not be shown again.")
+			}
+		}
+		
+		superAdmin := models.User{
+			Email:    "superadmin@tastygo.com",
+			Username: "superadmin",
+			Role:     models.RoleSuperAdmin,
+			Active:   true,
+			Profile: models.UserProfile{
+				FullName: "Super Admin",
+			},
+		}
+		
+		err = superAdmin.SetPassword(defaultPassword)
+		if err != nil {
+			return nil, err
+		}
+		
+		result := db.Create(&superAdmin)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		
+		log.Println("Created default superadmin account")
+	}
+	
+	return db, nil
+}
+
+// migrateSchema kiểm tra checksum các migration đã áp dụng và chạy các migration đang chờ.
+// Nếu requireMigrated là true, trả về ErrPendingMigrations thay vì tự áp dụng.
+func migrateSchema(db *gorm.DB, driver string, requireMigrated bool) error {
+	migrationFS, err := MigrationFS(driver)
+	if err != nil {
+		return err
+	}
+	
+	migrator, err := NewMigrator(db, migrationFS)
+	if err != nil {
+		return err
+	}
+	
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if requireMigrated {
+		return fmt.Errorf("%w (%d pending)", ErrPendingMigrations, len(pending))
+	}
+	
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		log.Printf("Applied migration %03d_%s", migration.Version, migration.Name)
+	}
+	return err
+}
+
+// seedRoles tạo các role hệ thống nếu chưa có, không ghi đè quyền đã được chỉnh sửa
+func seedRoles(db *gorm.DB) error {
+	for role, permissions := range models.DefaultRolePermissions {
+		var count int64
+		db.Model(&models.RoleDefinition{}).Where("name = ?", role).Count(&count)
+		if count > 0 {
+			continue
+		}
+		
+		definition := models.RoleDefinition{
+			Name:        role,
+			Description: "Built-in " + string(role) + " role",
+			System:      true,
+		}
+		for _, permission := range permissions {
+			definition.Permissions = append(definition.Permissions, models.RolePermission{
+				Role:       role,
+				Permission: permission,
+			})
+		}
+		
+		if err := db.Create(&definition).Error; err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/logging"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold là ngưỡng để một truy vấn bị ghi log như truy vấn chậm
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger chuyển log của GORM qua internal/logging để đi qua redactor và các sink.
+// Câu SQL chỉ được ghi với placeholder, không bao giờ kèm giá trị bind
+type gormLogger struct {
+	level logger.LogLevel
+}
+
+// newGormLogger tạo logger GORM theo tên mức log, mặc định là warn
+func newGormLogger(level string) logger.Interface {
+	switch level {
+	case "silent":
+		return gormLogger{level: logger.Silent}
+	case "error":
+		return gormLogger{level: logger.Error}
+	case "info":
+		return gormLogger{level: logger.Info}
+	default:
+		return gormLogger{level: logger.Warn}
+	}
+}
+
+func (l gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	l.level = level
+	return l
+}
+
+func (l gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		logging.FromContext(ctx).Info(fmt.Sprintf(msg, args...), nil)
+	}
+}
+
+func (l gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		logging.FromContext(ctx).Warn(fmt.Sprintf(msg, args...), nil)
+	}
+}
+
+func (l gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		logging.FromContext(ctx).Error(fmt.Sprintf(msg, args...), nil)
+	}
+}
+
+// Trace ghi log lỗi truy vấn (trừ không tìm thấy bản ghi), truy vấn chậm, và mọi truy vấn ở mức info
+func (l gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		logging.FromContext(ctx).Error("Database query failed", map[string]interface{}{
+			"sql":        sql,
+			"rows":       rows,
+			"elapsed_ms": elapsed.Milliseconds(),
+			"error":      err.Error(),
+		})
+	case elapsed >= slowQueryThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		logging.FromContext(ctx).Warn("Slow database query", map[string]interface{}{
+			"sql":        sql,
+			"rows":       rows,
+			"elapsed_ms": elapsed.Milliseconds(),
+		})
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		logging.FromContext(ctx).Debug("Database query", map[string]interface{}{
+			"sql":        sql,
+			"rows":       rows,
+			"elapsed_ms": elapsed.Milliseconds(),
+		})
+	}
+}
+
+// ParamsFilter bỏ giá trị bind trước khi GORM dựng câu SQL để ghi log, log chỉ chứa placeholder
+func (l gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
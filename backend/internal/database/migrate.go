@@ -0,0 +1,336 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPendingMigrations = errors.New("database has pending migrations, run `tastygo migrate up` first")
+	ErrChecksumMismatch  = errors.New("applied migration has been modified")
+	ErrMissingMigration  = errors.New("applied migration is missing from the migration files")
+	ErrNoDownScript      = errors.New("migration has no down script")
+)
+
+// Tên file migration: <version>_<name>.up.sql hoặc <version>_<name>.down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration là một phiên bản schema gồm script up và down
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// AppliedMigration là một bản ghi trong bảng schema_migrations
+type AppliedMigration struct {
+	Version   int64     `gorm:"primarykey"`
+	Name      string    `gorm:"not null"`
+	Checksum  string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName đặt tên bảng lưu lịch sử migration
+func (AppliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus là trạng thái của một migration, dùng cho lệnh `migrate status`
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Modified cho biết file migration đã bị sửa sau khi được áp dụng
+	Modified bool
+	// Missing cho biết migration đã áp dụng nhưng không còn file tương ứng
+	Missing bool
+}
+
+// Migrator áp dụng và hoàn tác các migration theo thứ tự phiên bản
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator đọc các file migration từ fsys và tạo Migrator cho db
+func NewMigrator(db *gorm.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LoadMigrations đọc và ghép cặp các file up/down, sắp xếp theo phiên bản
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		sum := sha256.Sum256([]byte(migration.Up))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// ensureTable tạo bảng schema_migrations nếu chưa có
+func (m *Migrator) ensureTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`).Error
+}
+
+// applied trả về các migration đã áp dụng, theo phiên bản tăng dần
+func (m *Migrator) applied() ([]AppliedMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var rows []AppliedMigration
+	err := m.db.Order("version").Find(&rows).Error
+	return rows, err
+}
+
+// Verify kiểm tra các migration đã áp dụng còn khớp với file hiện tại
+func (m *Migrator) Verify() error {
+	rows, err := m.applied()
+	if err != nil {
+		return err
+	}
+	return m.verify(rows)
+}
+
+func (m *Migrator) verify(rows []AppliedMigration) error {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for _, row := range rows {
+		migration, ok := known[row.Version]
+		if !ok {
+			return fmt.Errorf("%w: %d_%s", ErrMissingMigration, row.Version, row.Name)
+		}
+		if migration.Checksum != row.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, row.Version, row.Name)
+		}
+	}
+	return nil
+}
+
+// Pending trả về các migration chưa được áp dụng
+func (m *Migrator) Pending() ([]Migration, error) {
+	rows, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verify(rows); err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]bool, len(rows))
+	for _, row := range rows {
+		done[row.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up áp dụng tất cả migration đang chờ, mỗi migration trong một transaction
+func (m *Migrator) Up() ([]Migration, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range pending {
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&AppliedMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return pending, nil
+}
+
+// Down hoàn tác steps migration gần nhất theo thứ tự ngược
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	rows, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verify(rows); err != nil {
+		return nil, err
+	}
+
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	for i := len(rows) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := known[rows[i].Version]
+		if strings.TrimSpace(migration.Down) == "" {
+			return reverted, fmt.Errorf("%w: %d_%s", ErrNoDownScript, migration.Version, migration.Name)
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Where("version = ?", migration.Version).Delete(&AppliedMigration{}).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("rollback of %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// Status trả về trạng thái của mọi migration, kể cả migration đã áp dụng nhưng mất file
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	rows, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	appliedByVersion := make(map[int64]AppliedMigration, len(rows))
+	for _, row := range rows {
+		appliedByVersion[row.Version] = row
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := appliedByVersion[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.Modified = row.Checksum != migration.Checksum
+			delete(appliedByVersion, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range appliedByVersion {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   row.Version,
+			Name:      row.Name,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// CreateMigration tạo cặp file up/down rỗng với phiên bản kế tiếp trong thư mục dir
+func CreateMigration(dir, name string) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	if !regexp.MustCompile(`^[a-z0-9_]+$`).MatchString(name) {
+		return "", "", fmt.Errorf("invalid migration name: %q", name)
+	}
+
+	migrations, err := LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+
+	var next int64 = 1
+	if len(migrations) > 0 {
+		next = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%03d_%s", next, name)
+	upPath := filepath.Join(dir, base+".up.sql")
+	downPath := filepath.Join(dir, base+".down.sql")
+
+	if err := os.WriteFile(upPath, []byte("-- "+name+"\n"), 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte("-- Hoàn tác "+name+"\n"), 0644); err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/tastygo/internal/database"
+	"github.com/yourusername/tastygo/internal/lifecycle"
+	"gorm.io/gorm"
+)
+
+// ReadinessChecker thất bại khi server chưa khởi động xong hoặc đang shutdown
+func ReadinessChecker(lc *lifecycle.Manager) Checker {
+	return CheckerFunc("lifecycle", func(ctx context.Context) error {
+		if !lc.Ready() {
+			return errors.New("server is not accepting traffic")
+		}
+		return nil
+	})
+}
+
+// DatabaseChecker ping database qua connection pool
+func DatabaseChecker(db *gorm.DB) Checker {
+	return CheckerFunc("database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+}
+
+// MigrationsChecker thất bại khi còn migration chưa áp dụng hoặc migration đã áp dụng bị sửa
+func MigrationsChecker(migrator *database.Migrator) Checker {
+	return CheckerFunc("migrations", func(ctx context.Context) error {
+		pending, err := migrator.Pending()
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d pending migration(s), first is %03d_%s", len(pending), pending[0].Version, pending[0].Name)
+		}
+		return nil
+	})
+}
+
+// WorkersChecker thất bại khi có worker nền đã dừng
+func WorkersChecker(lc *lifecycle.Manager) Checker {
+	return CheckerFunc("workers", func(ctx context.Context) error {
+		var stopped []string
+		for name, running := range lc.Workers() {
+			if !running {
+				stopped = append(stopped, name)
+			}
+		}
+		if len(stopped) > 0 {
+			sort.Strings(stopped)
+			return fmt.Errorf("background workers stopped: %s", strings.Join(stopped, ", "))
+		}
+		return nil
+	})
+}
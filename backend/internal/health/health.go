@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Trạng thái của một kiểm tra và của cả báo cáo
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Checker là một kiểm tra sức khỏe mà các thành phần (database, cache, mailer...) đăng ký vào Registry
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// checkerFunc cho phép dùng một hàm làm Checker
+type checkerFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c checkerFunc) Name() string                    { return c.name }
+func (c checkerFunc) Check(ctx context.Context) error { return c.fn(ctx) }
+
+// CheckerFunc tạo Checker từ tên và hàm kiểm tra
+func CheckerFunc(name string, fn func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, fn: fn}
+}
+
+// Result là kết quả của một kiểm tra
+type Result struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report là kết quả tổng hợp; Status là "ok" khi mọi kiểm tra đều thành công
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Healthy cho biết mọi kiểm tra trong báo cáo đều thành công
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Registry giữ danh sách Checker và chạy chúng song song với timeout cho từng kiểm tra
+type Registry struct {
+	mu       sync.RWMutex
+	checkers []Checker
+	timeout  time.Duration
+}
+
+// NewRegistry tạo Registry với timeout cho mỗi kiểm tra
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// Register thêm các Checker vào Registry
+func (r *Registry) Register(checkers ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkers = append(r.checkers, checkers...)
+}
+
+// Run chạy tất cả kiểm tra và trả về báo cáo theo thứ tự đăng ký
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]Checker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = r.run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// run chạy một kiểm tra; kiểm tra không trả lời trước timeout được xem là lỗi
+func (r *Registry) run(ctx context.Context, checker Checker) Result {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{
+		Name:     checker.Name(),
+		Status:   StatusOK,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/yourusername/tastygo/internal/logging"
+)
+
+// hook là một tác vụ chạy khi shutdown
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// worker là một goroutine nền được Manager quản lý
+type worker struct {
+	name    string
+	done    chan struct{}
+	running atomic.Bool
+}
+
+// Manager quản lý trạng thái sẵn sàng, các worker nền và thứ tự shutdown của ứng dụng
+type Manager struct {
+	ready atomic.Bool
+
+	mu      sync.Mutex
+	hooks   []hook
+	workers []*worker
+	stopped bool
+}
+
+// NewManager tạo Manager ở trạng thái chưa sẵn sàng
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// SetReady đặt trạng thái sẵn sàng nhận traffic
+func (m *Manager) SetReady(ready bool) {
+	m.ready.Store(ready)
+}
+
+// Ready cho biết ứng dụng có đang sẵn sàng nhận traffic không
+func (m *Manager) Ready() bool {
+	return m.ready.Load()
+}
+
+// OnShutdown đăng ký hook chạy khi shutdown; các hook chạy lần lượt theo thứ tự đăng ký
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// Go chạy run trong một goroutine nền. Khi shutdown tới lượt, ctx của worker bị hủy
+// và Manager chờ worker kết thúc trước khi chạy hook tiếp theo
+func (m *Manager) Go(name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &worker{name: name, done: make(chan struct{})}
+	w.running.Store(true)
+
+	m.mu.Lock()
+	m.workers = append(m.workers, w)
+	m.mu.Unlock()
+
+	go func() {
+		defer close(w.done)
+		defer w.running.Store(false)
+		defer func() {
+			if r := recover(); r != nil {
+				logging.Error("Background worker panicked", map[string]interface{}{
+					"worker": name,
+					"panic":  fmt.Sprint(r),
+				})
+			}
+		}()
+		run(ctx)
+	}()
+
+	m.OnShutdown(name, func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-w.done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	})
+}
+
+// Workers trả về trạng thái đang chạy của từng worker nền theo tên
+func (m *Manager) Workers() map[string]bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	workers := make(map[string]bool, len(m.workers))
+	for _, w := range m.workers {
+		workers[w.name] = w.running.Load()
+	}
+	return workers
+}
+
+// Shutdown chuyển sang trạng thái chưa sẵn sàng rồi chạy các hook theo thứ tự đăng ký.
+// Hook lỗi không chặn các hook sau; mọi lỗi được gộp lại và trả về
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.SetReady(false)
+
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	hooks := append([]hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, h := range hooks {
+		if err := h.fn(ctx); err != nil {
+			logging.Error("Shutdown hook failed", map[string]interface{}{
+				"hook":  h.name,
+				"error": err.Error(),
+			})
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		logging.Info("Shutdown hook completed", map[string]interface{}{
+			"hook": h.name,
+		})
+	}
+	return errors.Join(errs...)
+}
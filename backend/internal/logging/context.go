@@ -0,0 +1,62 @@
+package logging
+
+import (
+    "context"
+    "sync/atomic"
+    "time"
+)
+
+type contextKey struct{}
+
+// RequestInfo là thông tin của một HTTP request, được gắn vào mọi log ghi qua FromContext
+type RequestInfo struct {
+    ID     string
+    Method string
+    Route  string
+    Start  time.Time
+
+    // userID được gán sau khi AuthMiddleware xác thực xong nên cần đọc/ghi an toàn
+    userID atomic.Uint64
+}
+
+// SetUserID gán user đã xác thực cho request
+func (r *RequestInfo) SetUserID(id uint) {
+    if r != nil {
+        r.userID.Store(uint64(id))
+    }
+}
+
+// UserID trả về user đã xác thực, 0 nếu request chưa xác thực
+func (r *RequestInfo) UserID() uint {
+    if r == nil {
+        return 0
+    }
+    return uint(r.userID.Load())
+}
+
+// WithRequest gắn RequestInfo vào context
+func WithRequest(ctx context.Context, info *RequestInfo) context.Context {
+    return context.WithValue(ctx, contextKey{}, info)
+}
+
+// RequestFromContext trả về RequestInfo trong context, nil nếu không có
+func RequestFromContext(ctx context.Context) *RequestInfo {
+    if ctx == nil {
+        return nil
+    }
+    info, _ := ctx.Value(contextKey{}).(*RequestInfo)
+    return info
+}
+
+// FromContext trả về logger tự động gắn request_id, user_id, route và latency của
+// request trong ctx. Nếu ctx không thuộc request nào, trả về DefaultLogger
+func FromContext(ctx context.Context) *Logger {
+    info := RequestFromContext(ctx)
+    if info == nil {
+        return DefaultLogger
+    }
+    return &Logger{
+        core:    DefaultLogger.core,
+        request: info,
+    }
+}
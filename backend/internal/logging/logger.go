@@ -2,10 +2,14 @@ package logging
 
 import (
     "encoding/json"
+    "errors"
     "fmt"
     "io"
     "os"
     "runtime"
+    "strings"
+    "sync"
+    "sync/atomic"
     "time"
 )
 
@@ -25,10 +29,46 @@ func (l LogLevel) String() string {
     return [...]string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}[l]
 }
 
+// ParseLevel chuyển tên cấp độ (không phân biệt hoa thường) thành LogLevel
+func ParseLevel(name string) (LogLevel, error) {
+    switch strings.ToUpper(strings.TrimSpace(name)) {
+    case "DEBUG":
+        return DEBUG, nil
+    case "INFO":
+        return INFO, nil
+    case "WARN", "WARNING":
+        return WARN, nil
+    case "ERROR":
+        return ERROR, nil
+    case "FATAL":
+        return FATAL, nil
+    }
+    return INFO, fmt.Errorf("unknown log level: %q", name)
+}
+
+// Sink là một đích ghi log với cấp độ tối thiểu riêng
+type Sink struct {
+    Name     string
+    Writer   io.Writer
+    MinLevel LogLevel
+}
+
+// core là phần dùng chung giữa logger gốc và các logger lấy từ FromContext,
+// để thay đổi level hoặc sink có hiệu lực với mọi logger
+type core struct {
+    level   atomic.Int32
+    mu      sync.Mutex
+    sinks    []Sink
+    sampler  *sampler
+    redactor *Redactor
+}
+
 // Logger cung cấp chức năng ghi log có cấu trúc
 type Logger struct {
-    Level  LogLevel
-    Writer io.Writer
+    core *core
+
+    // request là request hiện tại, được gắn vào mọi bản ghi khi logger lấy từ FromContext
+    request *RequestInfo
 }
 
 // LogEntry đại diện cho một bản ghi log
@@ -39,24 +79,124 @@ type LogEntry struct {
     File      string      `json:"file,omitempty"`
     Line      int         `json:"line,omitempty"`
     Data      interface{} `json:"data,omitempty"`
+
+    // Thông tin request, chỉ có khi log qua FromContext
+    RequestID string  `json:"request_id,omitempty"`
+    UserID    uint    `json:"user_id,omitempty"`
+    Method    string  `json:"method,omitempty"`
+    Route     string  `json:"route,omitempty"`
+    LatencyMS float64 `json:"latency_ms,omitempty"`
 }
 
-// NewLogger tạo một logger mới
+// NewLogger tạo một logger mới ghi ra stdout, che dữ liệu nhạy cảm bằng DefaultRedactor
 func NewLogger(level LogLevel) *Logger {
-    return &Logger{
-        Level:  level,
-        Writer: os.Stdout,
-    }
+    l := &Logger{core: &core{}}
+    l.core.level.Store(int32(level))
+    l.core.sinks = []Sink{{Name: "stdout", Writer: os.Stdout, MinLevel: DEBUG}}
+    l.core.redactor = DefaultRedactor()
+    return l
 }
 
-// SetOutput thiết lập writer cho logger
+// SetRedactor thay Redactor áp dụng cho message và data của mọi bản ghi; nil là tắt redaction
+func (l *Logger) SetRedactor(redactor *Redactor) {
+    l.core.mu.Lock()
+    defer l.core.mu.Unlock()
+
+    l.core.redactor = redactor
+}
+
+// SetLevel thay đổi cấp độ tối thiểu, an toàn khi gọi lúc đang chạy
+func (l *Logger) SetLevel(level LogLevel) {
+    l.core.level.Store(int32(level))
+}
+
+// Level trả về cấp độ tối thiểu hiện tại
+func (l *Logger) Level() LogLevel {
+    return LogLevel(l.core.level.Load())
+}
+
+// SetOutput thay toàn bộ sink bằng một writer duy nhất
 func (l *Logger) SetOutput(w io.Writer) {
-    l.Writer = w
+    l.SetSinks([]Sink{{Name: "output", Writer: w, MinLevel: DEBUG}})
+}
+
+// AddSink thêm một đích ghi log; bản ghi chỉ được ghi vào sink khi đạt cả level
+// của logger và MinLevel của sink
+func (l *Logger) AddSink(sink Sink) {
+    l.core.mu.Lock()
+    defer l.core.mu.Unlock()
+
+    l.core.sinks = append(l.core.sinks, sink)
+}
+
+// SetSinks thay toàn bộ sink
+func (l *Logger) SetSinks(sinks []Sink) {
+    l.core.mu.Lock()
+    defer l.core.mu.Unlock()
+
+    l.core.sinks = append([]Sink(nil), sinks...)
+}
+
+// Sinks trả về bản sao danh sách sink hiện tại
+func (l *Logger) Sinks() []Sink {
+    l.core.mu.Lock()
+    defer l.core.mu.Unlock()
+
+    return append([]Sink(nil), l.core.sinks...)
+}
+
+// SetDebugSampling giới hạn log DEBUG lặp lại: trong mỗi khoảng tick, mỗi message chỉ
+// ghi first lần đầu, sau đó cứ thereafter lần mới ghi một lần. first <= 0 tắt sampling
+func (l *Logger) SetDebugSampling(first, thereafter int, tick time.Duration) {
+    l.core.mu.Lock()
+    defer l.core.mu.Unlock()
+
+    if first <= 0 {
+        l.core.sampler = nil
+        return
+    }
+    l.core.sampler = newSampler(first, thereafter, tick)
+}
+
+// Flush đẩy dữ liệu còn trong bộ đệm của các sink (file...) xuống đĩa
+func (l *Logger) Flush() error {
+    var errs []error
+    for _, sink := range l.Sinks() {
+        if syncer, ok := sink.Writer.(interface{ Sync() error }); ok {
+            if err := syncer.Sync(); err != nil {
+                errs = append(errs, fmt.Errorf("%s: %w", sink.Name, err))
+            }
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// Close flush và đóng các sink có thể đóng (trừ stdout/stderr)
+func (l *Logger) Close() error {
+    errs := []error{l.Flush()}
+    for _, sink := range l.Sinks() {
+        if sink.Writer == os.Stdout || sink.Writer == os.Stderr {
+            continue
+        }
+        if closer, ok := sink.Writer.(io.Closer); ok {
+            if err := closer.Close(); err != nil {
+                errs = append(errs, fmt.Errorf("%s: %w", sink.Name, err))
+            }
+        }
+    }
+    return errors.Join(errs...)
 }
 
 // log ghi một bản ghi log
 func (l *Logger) log(level LogLevel, msg string, data interface{}) {
-    if level < l.Level {
+    if level < l.Level() {
+        return
+    }
+
+    l.core.mu.Lock()
+    sampler, redactor := l.core.sampler, l.core.redactor
+    l.core.mu.Unlock()
+    if level == DEBUG && sampler != nil && !sampler.allow(msg) {
         return
     }
 
@@ -75,17 +215,40 @@ func (l *Logger) log(level LogLevel, msg string, data interface{}) {
         Line:      line,
         Data:      data,
     }
+    if redactor != nil {
+        entry.Message = redactor.RedactString(msg)
+        entry.Data = redactor.Redact(data)
+    }
+    if l.request != nil {
+        entry.RequestID = l.request.ID
+        entry.UserID = l.request.UserID()
+        entry.Method = l.request.Method
+        entry.Route = l.request.Route
+        entry.LatencyMS = float64(time.Since(l.request.Start).Microseconds()) / 1000
+    }
 
     jsonData, err := json.Marshal(entry)
     if err != nil {
         fmt.Fprintf(os.Stderr, "Error marshaling log entry: %v\n", err)
         return
     }
+    jsonData = append(jsonData, '\n')
 
-    fmt.Fprintln(l.Writer, string(jsonData))
+    // Giữ lock khi ghi để các dòng từ nhiều goroutine không chen vào nhau
+    l.core.mu.Lock()
+    for _, sink := range l.core.sinks {
+        if level < sink.MinLevel {
+            continue
+        }
+        if _, err := sink.Writer.Write(jsonData); err != nil {
+            fmt.Fprintf(os.Stderr, "Error writing log to %s: %v\n", sink.Name, err)
+        }
+    }
+    l.core.mu.Unlock()
     
-    // Thoát chương trình nếu là log FATAL
+    // Flush các sink rồi thoát chương trình nếu là log FATAL
     if level == FATAL {
+        l.Close()
         os.Exit(1)
     }
 }
@@ -158,21 +321,3 @@ func Error(msg string, data ...interface{}) {
 func Fatal(msg string, data ...interface{}) {
     DefaultLogger.Fatal(msg, data...)
 }
-
-// Khởi tạo logger
-func init() {
-    // Thiết lập cấp độ log từ biến môi trường
-    logLevel := os.Getenv("LOG_LEVEL")
-    switch logLevel {
-    case "DEBUG":
-        DefaultLogger.Level = DEBUG
-    case "INFO":
-        DefaultLogger.Level = INFO
-    case "WARN":
-        DefaultLogger.Level = WARN
-    case "ERROR":
-        DefaultLogger.Level = ERROR
-    case "FATAL":
-        DefaultLogger.Level = FATAL
-    }
-}
\ No newline at end of file
@@ -0,0 +1,261 @@
+package logging
+
+import (
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "regexp"
+    "sort"
+    "strings"
+    "unicode"
+)
+
+// RedactMode là cách xử lý một giá trị nhạy cảm
+type RedactMode string
+
+const (
+    // RedactDrop xóa hẳn trường khỏi log (với pattern: xóa đoạn khớp)
+    RedactDrop RedactMode = "drop"
+    // RedactMask thay giá trị bằng RedactedValue
+    RedactMask RedactMode = "mask"
+    // RedactHash thay giá trị bằng HMAC-SHA256 rút gọn, cùng giá trị cho ra cùng hash
+    // nên vẫn tương quan được các bản ghi mà không lộ dữ liệu gốc
+    RedactHash RedactMode = "hash"
+)
+
+// RedactedValue là chuỗi thay thế cho giá trị bị mask
+const RedactedValue = "[REDACTED]"
+
+// ParseRedactMode chuyển tên mode thành RedactMode
+func ParseRedactMode(name string) (RedactMode, error) {
+    switch mode := RedactMode(strings.ToLower(strings.TrimSpace(name))); mode {
+    case RedactDrop, RedactMask, RedactHash:
+        return mode, nil
+    }
+    return "", fmt.Errorf("unknown redact mode: %q", name)
+}
+
+// ParseRedactKeys chuyển danh sách "key:mode" phân cách bằng dấu phẩy thành map key nhạy cảm.
+// Key không ghi mode được mask, phần tử rỗng bị bỏ qua
+func ParseRedactKeys(spec string) (map[string]RedactMode, error) {
+    keys := make(map[string]RedactMode)
+    for _, item := range strings.Split(spec, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        key, modeName, found := strings.Cut(item, ":")
+        mode := RedactMask
+        if found {
+            var err error
+            if mode, err = ParseRedactMode(modeName); err != nil {
+                return nil, err
+            }
+        }
+        keys[strings.TrimSpace(key)] = mode
+    }
+    return keys, nil
+}
+
+// RedactPattern là biểu thức chính quy tìm dữ liệu nhạy cảm trong chuỗi bất kỳ
+type RedactPattern struct {
+    Name   string
+    Regexp *regexp.Regexp
+    Mode   RedactMode
+}
+
+// Các pattern mặc định: JWT và header Authorization dạng bearer
+var (
+    JWTPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+    BearerPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// DefaultRedactKeys là các key nhạy cảm mặc định và mode tương ứng
+var DefaultRedactKeys = map[string]RedactMode{
+    "password":      RedactDrop,
+    "token":         RedactMask,
+    "authorization": RedactMask,
+    "email":         RedactHash,
+    "ip":            RedactHash,
+}
+
+// Redactor che các giá trị nhạy cảm trong dữ liệu log. Key được so khớp không phân biệt
+// hoa thường, tại đầu một đoạn của tên trường: "token" khớp "refresh_token" và "accessToken",
+// "ip" khớp "client_ip" và "ip_address" nhưng không khớp "description" hay "zip"
+type Redactor struct {
+    keys     []redactKey
+    patterns []RedactPattern
+    hashKey  []byte
+}
+
+// redactKey là một key nhạy cảm đã chuẩn hóa về chữ thường
+type redactKey struct {
+    name string
+    mode RedactMode
+}
+
+// redactModeStrictness xếp các mode theo mức che giấu, dùng khi hai key dài bằng nhau cùng khớp
+var redactModeStrictness = map[RedactMode]int{RedactDrop: 0, RedactMask: 1, RedactHash: 2}
+
+// NewRedactor tạo Redactor. hashKey dùng cho mode hash; để trống thì sinh key ngẫu nhiên,
+// khi đó hash chỉ tương quan được trong cùng một process
+func NewRedactor(keys map[string]RedactMode, patterns []RedactPattern, hashKey []byte) *Redactor {
+    // Thứ tự cố định để một tên khớp nhiều key luôn cho cùng kết quả: key dài (cụ thể) hơn
+    // trước, cùng độ dài thì mode che giấu nhiều hơn trước
+    normalized := make([]redactKey, 0, len(keys))
+    for key, mode := range keys {
+        normalized = append(normalized, redactKey{name: strings.ToLower(key), mode: mode})
+    }
+    sort.Slice(normalized, func(i, j int) bool {
+        a, b := normalized[i], normalized[j]
+        if len(a.name) != len(b.name) {
+            return len(a.name) > len(b.name)
+        }
+        if a.mode != b.mode {
+            return redactModeStrictness[a.mode] < redactModeStrictness[b.mode]
+        }
+        return a.name < b.name
+    })
+
+    if len(hashKey) == 0 {
+        hashKey = make([]byte, 32)
+        rand.Read(hashKey)
+    }
+
+    return &Redactor{keys: normalized, patterns: patterns, hashKey: hashKey}
+}
+
+// DefaultRedactor che các key mặc định, mask JWT và bearer token trong mọi chuỗi
+func DefaultRedactor() *Redactor {
+    return NewRedactor(DefaultRedactKeys, []RedactPattern{
+        {Name: "jwt", Regexp: JWTPattern, Mode: RedactMask},
+        {Name: "bearer", Regexp: BearerPattern, Mode: RedactMask},
+    }, nil)
+}
+
+// keyMode trả về mode của key nhạy cảm đầu tiên khớp với name
+func (r *Redactor) keyMode(name string) (RedactMode, bool) {
+    lower := strings.ToLower(name)
+    for _, key := range r.keys {
+        for i := 0; i+len(key.name) <= len(lower); i++ {
+            if segmentStart(name, i) && strings.HasPrefix(lower[i:], key.name) {
+                return key.mode, true
+            }
+        }
+    }
+    return "", false
+}
+
+// segmentStart cho biết vị trí i có phải đầu một đoạn của tên trường: đầu chuỗi, sau ký tự
+// phân cách như "_", "-", "." hoặc chữ hoa đứng sau chữ thường (camelCase)
+func segmentStart(name string, i int) bool {
+    if i == 0 {
+        return true
+    }
+    prev, cur := rune(name[i-1]), rune(name[i])
+    if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+        return true
+    }
+    return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// hash trả về HMAC-SHA256 rút gọn của value
+func (r *Redactor) hash(value string) string {
+    mac := hmac.New(sha256.New, r.hashKey)
+    mac.Write([]byte(value))
+    return "hash:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// RedactString áp dụng các pattern cho một chuỗi
+func (r *Redactor) RedactString(s string) string {
+    for _, pattern := range r.patterns {
+        s = pattern.Regexp.ReplaceAllStringFunc(s, func(match string) string {
+            switch pattern.Mode {
+            case RedactDrop:
+                return ""
+            case RedactHash:
+                return r.hash(match)
+            default:
+                return RedactedValue
+            }
+        })
+    }
+    return s
+}
+
+// Redact trả về bản sao của v với mọi giá trị nhạy cảm đã được xử lý, duyệt đệ quy
+// qua map và slice. Struct và kiểu khác được chuyển qua JSON trước để duyệt theo tên trường
+func (r *Redactor) Redact(v interface{}) interface{} {
+    if r == nil || v == nil {
+        return v
+    }
+
+    switch value := v.(type) {
+    case string:
+        return r.RedactString(value)
+    case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+        return value
+    case map[string]interface{}:
+        out := make(map[string]interface{}, len(value))
+        for key, item := range value {
+            r.redactField(out, key, item)
+        }
+        return out
+    case map[string]string:
+        out := make(map[string]interface{}, len(value))
+        for key, item := range value {
+            r.redactField(out, key, item)
+        }
+        return out
+    case []interface{}:
+        out := make([]interface{}, len(value))
+        for i, item := range value {
+            out[i] = r.Redact(item)
+        }
+        return out
+    case []string:
+        out := make([]interface{}, len(value))
+        for i, item := range value {
+            out[i] = r.RedactString(item)
+        }
+        return out
+    case error:
+        return r.RedactString(value.Error())
+    }
+
+    // Kiểu còn lại (struct, slice/map kiểu khác...): chuyển về dạng JSON tổng quát rồi duyệt
+    raw, err := json.Marshal(v)
+    if err != nil {
+        return RedactedValue
+    }
+    var generic interface{}
+    if err := json.Unmarshal(raw, &generic); err != nil {
+        return RedactedValue
+    }
+    return r.Redact(generic)
+}
+
+// redactField ghi key vào out sau khi xử lý theo mode của key (nếu là key nhạy cảm)
+func (r *Redactor) redactField(out map[string]interface{}, key string, value interface{}) {
+    mode, sensitive := r.keyMode(key)
+    if !sensitive || value == nil {
+        out[key] = r.Redact(value)
+        return
+    }
+
+    switch mode {
+    case RedactDrop:
+    case RedactHash:
+        if s, ok := value.(string); ok {
+            out[key] = r.hash(s)
+        } else {
+            raw, _ := json.Marshal(value)
+            out[key] = r.hash(string(raw))
+        }
+    default:
+        out[key] = RedactedValue
+    }
+}
@@ -0,0 +1,247 @@
+package logging
+
+import (
+    "compress/gzip"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Định dạng thời gian trong tên file đã rotate, sắp xếp theo thứ tự từ điển cũng là theo thời gian
+const backupTimeFormat = "20060102T150405.000"
+
+// RotateConfig là cấu hình cho RotatingFile
+type RotateConfig struct {
+    Path string
+    // MaxSize là kích thước tối đa (byte) trước khi rotate, 0 là không giới hạn
+    MaxSize int64
+    // Interval là chu kỳ rotate theo thời gian (ví dụ 24h), 0 là tắt
+    Interval time.Duration
+    // MaxBackups là số file cũ giữ lại, 0 là giữ tất cả
+    MaxBackups int
+    // Compress nén gzip các file đã rotate
+    Compress bool
+}
+
+// RotatingFile là io.Writer ghi vào file và tự rotate theo kích thước hoặc thời gian.
+// File cũ được đổi tên thành <path>.<thời gian>, nén gzip nếu bật Compress
+type RotatingFile struct {
+    config RotateConfig
+
+    mu         sync.Mutex
+    // file là nil khi đã Close, hoặc khi rotate lỗi giữa chừng; khi đó lần ghi sau mở lại file
+    file       *os.File
+    closed     bool
+    size       int64
+    nextRotate time.Time
+
+    // compressing theo dõi các goroutine nén để Close chờ chúng xong;
+    // backupMu đảm bảo chỉ một goroutine nén/xóa file cũ tại một thời điểm
+    compressing sync.WaitGroup
+    backupMu    sync.Mutex
+}
+
+// NewRotatingFile mở (hoặc tạo) file log theo cấu hình
+func NewRotatingFile(config RotateConfig) (*RotatingFile, error) {
+    if config.Path == "" {
+        return nil, fmt.Errorf("log file path is required")
+    }
+    if err := os.MkdirAll(filepath.Dir(config.Path), 0o755); err != nil {
+        return nil, err
+    }
+
+    r := &RotatingFile{config: config}
+    if err := r.open(); err != nil {
+        return nil, err
+    }
+    return r, nil
+}
+
+// open mở file hiện tại ở chế độ append
+func (r *RotatingFile) open() error {
+    file, err := os.OpenFile(r.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+    if err != nil {
+        return err
+    }
+    info, err := file.Stat()
+    if err != nil {
+        file.Close()
+        return err
+    }
+
+    r.file = file
+    r.size = info.Size()
+    if r.config.Interval > 0 {
+        r.nextRotate = time.Now().Truncate(r.config.Interval).Add(r.config.Interval)
+    }
+    return nil
+}
+
+// Write ghi p vào file, rotate trước nếu vượt kích thước hoặc đến chu kỳ
+func (r *RotatingFile) Write(p []byte) (int, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.closed {
+        return 0, os.ErrClosed
+    }
+
+    sizeExceeded := r.config.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.config.MaxSize
+    intervalElapsed := !r.nextRotate.IsZero() && !time.Now().Before(r.nextRotate)
+    if sizeExceeded || intervalElapsed {
+        // Rotate lỗi không được làm mất log: báo ra stderr và tiếp tục ghi vào file hiện tại
+        if err := r.rotate(); err != nil {
+            fmt.Fprintf(os.Stderr, "Error rotating log file %s: %v\n", r.config.Path, err)
+        }
+    }
+    if r.file == nil {
+        if err := r.open(); err != nil {
+            return 0, err
+        }
+    }
+
+    n, err := r.file.Write(p)
+    r.size += int64(n)
+    return n, err
+}
+
+// Rotate đóng file hiện tại, đổi tên thành bản sao lưu và mở file mới
+func (r *RotatingFile) Rotate() error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.closed {
+        return os.ErrClosed
+    }
+    return r.rotate()
+}
+
+// rotate đổi tên file hiện tại và mở file mới. Handle cũ không còn dùng được sau Close nên nếu
+// một bước lỗi, r.file được đặt về nil để lần ghi sau mở lại file thay vì ghi vào handle đã đóng
+func (r *RotatingFile) rotate() error {
+    if r.file != nil {
+        err := r.file.Close()
+        r.file = nil
+        if err != nil {
+            return err
+        }
+    }
+
+    backup := r.config.Path + "." + time.Now().Format(backupTimeFormat)
+    if err := os.Rename(r.config.Path, backup); err != nil {
+        return err
+    }
+    if err := r.open(); err != nil {
+        return err
+    }
+
+    r.compressing.Add(1)
+    go func() {
+        defer r.compressing.Done()
+        r.backupMu.Lock()
+        defer r.backupMu.Unlock()
+
+        if r.config.Compress {
+            if err := compressFile(backup); err != nil {
+                fmt.Fprintf(os.Stderr, "Error compressing log file %s: %v\n", backup, err)
+            }
+        }
+        r.pruneBackups()
+    }()
+    return nil
+}
+
+// pruneBackups xóa các file đã rotate cũ nhất khi vượt quá MaxBackups
+func (r *RotatingFile) pruneBackups() {
+    if r.config.MaxBackups <= 0 {
+        return
+    }
+
+    matches, err := filepath.Glob(r.config.Path + ".*")
+    if err != nil {
+        return
+    }
+
+    var backups []string
+    for _, match := range matches {
+        // Bỏ qua file tạm trong lúc nén
+        if !strings.HasSuffix(match, ".tmp") {
+            backups = append(backups, match)
+        }
+    }
+    sort.Strings(backups)
+
+    for len(backups) > r.config.MaxBackups {
+        os.Remove(backups[0])
+        backups = backups[1:]
+    }
+}
+
+// Sync đẩy dữ liệu của file hiện tại xuống đĩa
+func (r *RotatingFile) Sync() error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.file == nil {
+        return nil
+    }
+    return r.file.Sync()
+}
+
+// Close đóng file và chờ các file đang nén xong
+func (r *RotatingFile) Close() error {
+    r.mu.Lock()
+    var err error
+    r.closed = true
+    if r.file != nil {
+        r.file.Sync()
+        err = r.file.Close()
+        r.file = nil
+    }
+    r.mu.Unlock()
+
+    r.compressing.Wait()
+    return err
+}
+
+// compressFile nén path thành path.gz rồi xóa file gốc
+func compressFile(path string) error {
+    src, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer src.Close()
+
+    tmp := path + ".gz.tmp"
+    dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+    if err != nil {
+        return err
+    }
+
+    gz := gzip.NewWriter(dst)
+    if _, err := io.Copy(gz, src); err != nil {
+        dst.Close()
+        os.Remove(tmp)
+        return err
+    }
+    if err := gz.Close(); err != nil {
+        dst.Close()
+        os.Remove(tmp)
+        return err
+    }
+    if err := dst.Close(); err != nil {
+        os.Remove(tmp)
+        return err
+    }
+
+    if err := os.Rename(tmp, path+".gz"); err != nil {
+        return err
+    }
+    src.Close()
+    return os.Remove(path)
+}
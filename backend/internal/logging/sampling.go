@@ -0,0 +1,48 @@
+package logging
+
+import (
+    "sync"
+    "time"
+)
+
+// sampler đếm số lần mỗi message xuất hiện trong một khoảng tick để giảm log DEBUG lặp lại
+type sampler struct {
+    first      int
+    thereafter int
+    tick       time.Duration
+
+    mu     sync.Mutex
+    reset  time.Time
+    counts map[string]int
+}
+
+func newSampler(first, thereafter int, tick time.Duration) *sampler {
+    if tick <= 0 {
+        tick = time.Second
+    }
+    return &sampler{
+        first:      first,
+        thereafter: thereafter,
+        tick:       tick,
+        counts:     make(map[string]int),
+    }
+}
+
+// allow cho biết bản ghi với message msg có được ghi hay không
+func (s *sampler) allow(msg string) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    now := time.Now()
+    if now.After(s.reset) {
+        s.counts = make(map[string]int)
+        s.reset = now.Add(s.tick)
+    }
+
+    s.counts[msg]++
+    n := s.counts[msg]
+    if n <= s.first {
+        return true
+    }
+    return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
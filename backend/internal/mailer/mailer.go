@@ -0,0 +1,176 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message là một email cần gửi
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer là interface chung cho các cách gửi email
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// SMTPMailer gửi email qua máy chủ SMTP
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// NewSMTPMailer tạo mailer gửi qua SMTP
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+// Send gửi email qua SMTP (dùng STARTTLS nếu máy chủ hỗ trợ)
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.Host, m.Port)
+	return smtp.SendMail(addr, auth, m.From, []string{msg.To}, buildMessage(m.From, msg))
+}
+
+// Name trả về tên dùng trong báo cáo health check
+func (m *SMTPMailer) Name() string {
+	return "mailer"
+}
+
+// Check kiểm tra có thể kết nối tới máy chủ SMTP
+func (m *SMTPMailer) Check(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", m.Host, m.Port))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// MemoryMailer lưu email trong bộ nhớ, dùng cho test
+type MemoryMailer struct {
+	messages []Message
+	mu       sync.Mutex
+}
+
+// NewMemoryMailer tạo mailer lưu email trong bộ nhớ
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send lưu email vào danh sách
+func (m *MemoryMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages trả về bản sao các email đã gửi
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make([]Message, len(m.messages))
+	copy(messages, m.messages)
+	return messages
+}
+
+// Last trả về email gần nhất gửi tới địa chỉ to
+func (m *MemoryMailer) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// FileMailer ghi mỗi email thành một file .eml, tiện cho môi trường development
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+// NewFileMailer tạo mailer ghi email ra thư mục dir
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{Dir: dir, From: from}
+}
+
+// Send ghi email ra file
+func (m *FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), sanitizeFilename(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), buildMessage(m.From, msg), 0o600)
+}
+
+// Name trả về tên dùng trong báo cáo health check
+func (m *FileMailer) Name() string {
+	return "mailer"
+}
+
+// Check kiểm tra thư mục lưu email có ghi được không
+func (m *FileMailer) Check(ctx context.Context) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(m.Dir, ".healthcheck-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// buildMessage tạo nội dung email theo định dạng RFC 5322
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
+
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, s)
+}
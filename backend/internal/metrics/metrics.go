@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tastygo"
+
+// Kết quả đăng nhập dùng làm label "outcome" của LoginAttempts
+const (
+	LoginSuccess     = "success"
+	LoginMFARequired = "mfa_required"
+	LoginBadPassword = "bad_password"
+	LoginLocked      = "locked"
+	LoginDisabled    = "disabled"
+	LoginNotFound    = "not_found"
+)
+
+// Registry chứa tất cả metric của ứng dụng, được phục vụ tại /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests đếm request theo method, route template và status code
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration đo thời gian xử lý request theo method và route template
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// LoginAttempts đếm kết quả của auth.Login
+	LoginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_login_attempts_total",
+		Help:      "Login attempts by outcome.",
+	}, []string{"outcome"})
+
+	// CacheRequests đếm lượt đọc cache theo kết quả hit/miss
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by result (hit or miss).",
+	}, []string{"result"})
+
+	// RateLimitRejections đếm request bị từ chối do vượt giới hạn, theo route
+	RateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by the rate limiter by route.",
+	}, []string{"route"})
+
+	// DBQueryDuration đo thời gian truy vấn database theo loại thao tác và bảng
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation", "table"})
+)
+
+// activeSessionsSource là hàm đếm số phiên đăng nhập còn hiệu lực, được gọi mỗi lần scrape
+var activeSessionsSource atomic.Value
+
+// SetActiveSessionsSource đặt hàm dùng để tính gauge tastygo_auth_active_sessions
+func SetActiveSessionsSource(fn func() (int64, error)) {
+	activeSessionsSource.Store(fn)
+}
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		LoginAttempts,
+		CacheRequests,
+		RateLimitRejections,
+		DBQueryDuration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "auth_active_sessions",
+			Help:      "Number of sessions whose refresh token is still valid.",
+		}, func() float64 {
+			fn, ok := activeSessionsSource.Load().(func() (int64, error))
+			if !ok {
+				return 0
+			}
+			count, err := fn()
+			if err != nil {
+				return 0
+			}
+			return float64(count)
+		}),
+	)
+}
+
+// Handler trả về http.Handler phục vụ metric theo định dạng Prometheus text
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware ghi nhận số request và latency cho mỗi route. Dùng route template
+// (ví dụ /api/admin/users/:id) thay vì path thật để giới hạn số lượng label
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		HTTPRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		HTTPDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -7,12 +7,29 @@ import (
 type ActivityType string
 
 const (
-    ActivityLogin          ActivityType = "login"
-    ActivityLogout         ActivityType = "logout"
-    ActivityCreateUser     ActivityType = "create_user"
-    ActivityResetPassword  ActivityType = "reset_password"
-    ActivityUpdateStatus   ActivityType = "update_status"
-    ActivityUnlockAccount  ActivityType = "unlock_account"
+    ActivityLogin           ActivityType = "login"
+    ActivityLogout          ActivityType = "logout"
+    ActivityCreateUser      ActivityType = "create_user"
+    ActivityResetPassword   ActivityType = "reset_password"
+    ActivityUpdateStatus    ActivityType = "update_status"
+    ActivityUnlockAccount   ActivityType = "unlock_account"
+    ActivityRefreshToken    ActivityType = "refresh_token"
+    ActivityTokenReuse      ActivityType = "token_reuse"
+    ActivityRevokeSession   ActivityType = "revoke_session"
+    ActivityEnableMFA       ActivityType = "enable_mfa"
+    ActivityDisableMFA      ActivityType = "disable_mfa"
+    ActivityResetMFA        ActivityType = "reset_mfa"
+    ActivityUpdateMFAPolicy ActivityType = "update_mfa_policy"
+    ActivityManageRole      ActivityType = "manage_role"
+    ActivityRegister        ActivityType = "register"
+    ActivityVerifyEmail     ActivityType = "verify_email"
+    ActivityUpdateUser      ActivityType = "update_user"
+    ActivityDeleteUser      ActivityType = "delete_user"
+    ActivityRestoreUser     ActivityType = "restore_user"
+    ActivityUpdateProfile   ActivityType = "update_profile"
+    ActivityChangePassword  ActivityType = "change_password"
+    ActivityManageAPIKey    ActivityType = "manage_api_key"
+    ActivityAPIKeyUsed      ActivityType = "api_key_used"
 )
 
 type ActivityLog struct {
@@ -22,5 +39,23 @@ type ActivityLog struct {
     Description string       `json:"description"`
     IPAddress   string       `json:"ip_address"`
     UserAgent   string       `json:"user_agent"`
+    Changes     JSONText     `gorm:"type:text" json:"changes,omitempty"`
     CreatedAt   time.Time    `json:"created_at"`
 }
+
+// FieldChange ghi lại giá trị trước và sau của một trường bị thay đổi
+type FieldChange struct {
+    Before interface{} `json:"before"`
+    After  interface{} `json:"after"`
+}
+
+// JSONText là chuỗi JSON lưu dạng text, được trả về nguyên dạng trong response
+type JSONText string
+
+// MarshalJSON trả về nội dung JSON gốc thay vì chuỗi đã escape
+func (j JSONText) MarshalJSON() ([]byte, error) {
+    if j == "" {
+        return []byte("null"), nil
+    }
+    return []byte(j), nil
+}
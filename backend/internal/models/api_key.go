@@ -0,0 +1,43 @@
+package models
+
+import (
+    "time"
+)
+
+// APIKey cho phép partner và job nội bộ gọi API thay mặt user sở hữu mà không cần đăng nhập.
+// Key có dạng "tg_<prefix>_<secret>": prefix dùng để tra cứu và hiển thị, chỉ hash của secret
+// được lưu. Quyền của key là tập con quyền của role user sở hữu
+type APIKey struct {
+    ID          uint               `gorm:"primarykey" json:"id"`
+    Name        string             `gorm:"not null" json:"name"`
+    Prefix      string             `gorm:"uniqueIndex;not null" json:"prefix"`
+    SecretHash  string             `gorm:"not null" json:"-"`
+    UserID      uint               `gorm:"index;not null" json:"user_id"`
+    CreatedBy   uint               `gorm:"not null" json:"created_by"`
+    Permissions []APIKeyPermission `gorm:"foreignKey:APIKeyID;constraint:OnDelete:CASCADE" json:"-"`
+    ExpiresAt   *time.Time         `json:"expires_at"`
+    LastUsedAt  *time.Time         `json:"last_used_at"`
+    LastUsedIP  string             `json:"last_used_ip"`
+    RevokedAt   *time.Time         `json:"revoked_at"`
+    CreatedAt   time.Time          `json:"created_at"`
+}
+
+// APIKeyPermission gán một quyền cho một API key
+type APIKeyPermission struct {
+    APIKeyID   uint       `gorm:"primarykey" json:"api_key_id"`
+    Permission Permission `gorm:"primarykey" json:"permission"`
+}
+
+// PermissionNames trả về danh sách quyền của key dạng slice
+func (k *APIKey) PermissionNames() []Permission {
+    names := make([]Permission, 0, len(k.Permissions))
+    for _, p := range k.Permissions {
+        names = append(names, p.Permission)
+    }
+    return names
+}
+
+// IsActive kiểm tra key chưa bị thu hồi và chưa hết hạn
+func (k *APIKey) IsActive(now time.Time) bool {
+    return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
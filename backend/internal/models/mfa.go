@@ -0,0 +1,21 @@
+package models
+
+import (
+    "time"
+)
+
+// RecoveryCode là mã khôi phục dùng một lần khi user mất thiết bị TOTP
+type RecoveryCode struct {
+    ID        uint       `gorm:"primarykey" json:"id"`
+    UserID    uint       `gorm:"index;not null" json:"user_id"`
+    CodeHash  string     `gorm:"uniqueIndex;not null" json:"-"`
+    UsedAt    *time.Time `json:"used_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFAPolicy quy định role nào bắt buộc phải bật 2FA
+type MFAPolicy struct {
+    Role      Role      `gorm:"primarykey" json:"role"`
+    Required  bool      `gorm:"not null;default:false" json:"required"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+    "time"
+)
+
+// Permission là tên một quyền cụ thể, dạng "<tài nguyên>.<hành động>"
+type Permission string
+
+const (
+    PermAll                 Permission = "*"
+    PermDashboardView       Permission = "dashboard.view"
+    PermUsersRead           Permission = "users.read"
+    PermUsersCreate         Permission = "users.create"
+    PermUsersUpdate         Permission = "users.update"
+    PermUsersDelete         Permission = "users.delete"
+    PermUsersResetPassword  Permission = "users.reset_password"
+    PermUsersUpdateStatus   Permission = "users.update_status"
+    PermUsersUnlock         Permission = "users.unlock"
+    PermUsersRevokeSessions Permission = "users.revoke_sessions"
+    PermUsersResetMFA       Permission = "users.reset_mfa"
+    PermLogsRead            Permission = "logs.read"
+    PermLogLevelManage      Permission = "logs.level_manage"
+    PermRolesManage         Permission = "roles.manage"
+    PermMFAPolicyManage     Permission = "mfa.manage_policy"
+    PermAPIKeysManage       Permission = "api_keys.manage"
+    PermOrdersRead          Permission = "orders.read"
+    PermOrdersRefund        Permission = "orders.refund"
+)
+
+// AllPermissions liệt kê các quyền hợp lệ có thể gán cho role
+var AllPermissions = []Permission{
+    PermDashboardView,
+    PermUsersRead,
+    PermUsersCreate,
+    PermUsersUpdate,
+    PermUsersDelete,
+    PermUsersResetPassword,
+    PermUsersUpdateStatus,
+    PermUsersUnlock,
+    PermUsersRevokeSessions,
+    PermUsersResetMFA,
+    PermLogsRead,
+    PermLogLevelManage,
+    PermRolesManage,
+    PermMFAPolicyManage,
+    PermAPIKeysManage,
+    PermOrdersRead,
+    PermOrdersRefund,
+}
+
+// DefaultRolePermissions là tập quyền khởi tạo cho các role hệ thống. Các quyền vận hành
+// (logs.level_manage, api_keys.manage) chỉ có ở superadmin qua "*", role khác cần được gán riêng
+var DefaultRolePermissions = map[Role][]Permission{
+    RoleSuperAdmin: {PermAll},
+    RoleAdmin:      {PermDashboardView, PermOrdersRead, PermOrdersRefund},
+    RoleCustomer:   {},
+}
+
+// IsValidPermission kiểm tra tên quyền có nằm trong danh sách hợp lệ không
+func IsValidPermission(permission Permission) bool {
+    for _, p := range AllPermissions {
+        if p == permission {
+            return true
+        }
+    }
+    return false
+}
+
+// RoleDefinition định nghĩa một role là một tập các quyền, lưu trong database
+type RoleDefinition struct {
+    Name        Role             `gorm:"primarykey" json:"name"`
+    Description string           `json:"description"`
+    System      bool             `gorm:"default:false" json:"system"`
+    Permissions []RolePermission `gorm:"foreignKey:Role;references:Name;constraint:OnDelete:CASCADE" json:"-"`
+    CreatedAt   time.Time        `json:"created_at"`
+    UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// RolePermission gán một quyền cho một role
+type RolePermission struct {
+    Role       Role       `gorm:"primarykey" json:"role"`
+    Permission Permission `gorm:"primarykey" json:"permission"`
+}
+
+// PermissionNames trả về danh sách quyền của role dạng slice
+func (r *RoleDefinition) PermissionNames() []Permission {
+    names := make([]Permission, 0, len(r.Permissions))
+    for _, p := range r.Permissions {
+        names = append(names, p.Permission)
+    }
+    return names
+}
@@ -4,12 +4,25 @@ import (
     "time"
 )
 
+// Session đại diện cho một cặp access token / refresh token.
+// Mỗi lần refresh sẽ tạo một Session mới cùng FamilyID, session cũ được đánh dấu RotatedAt.
 type Session struct {
-    ID        uint      `gorm:"primarykey" json:"id"`
-    UserID    uint      `gorm:"index;not null" json:"user_id"`
-    Token     string    `gorm:"uniqueIndex;not null" json:"token"`
-    ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
-    CreatedAt time.Time `json:"created_at"`
-    IPAddress string    `json:"ip_address"`
-    UserAgent string    `json:"user_agent"`
+    ID               uint       `gorm:"primarykey" json:"id"`
+    UserID           uint       `gorm:"index;not null" json:"user_id"`
+    Token            string     `gorm:"uniqueIndex;not null" json:"-"`
+    ExpiresAt        time.Time  `gorm:"not null" json:"expires_at"`
+    CreatedAt        time.Time  `json:"created_at"`
+    IPAddress        string     `json:"ip_address"`
+    UserAgent        string     `json:"user_agent"`
+    FamilyID         string     `gorm:"index" json:"family_id"`
+    LoginAt          time.Time  `json:"login_at"`
+    RefreshTokenHash string     `gorm:"uniqueIndex" json:"-"`
+    RefreshExpiresAt time.Time  `json:"refresh_expires_at"`
+    RotatedAt        *time.Time `json:"-"`
+    RevokedAt        *time.Time `json:"-"`
+}
+
+// IsActive kiểm tra session còn có thể dùng để refresh hay không
+func (s *Session) IsActive(now time.Time) bool {
+    return s.RevokedAt == nil && s.RotatedAt == nil && s.RefreshExpiresAt.After(now)
 }
\ No newline at end of file
@@ -0,0 +1,26 @@
+package models
+
+import (
+    "time"
+)
+
+// PasswordResetToken lưu hash của token đặt lại mật khẩu, chỉ dùng được một lần
+type PasswordResetToken struct {
+    ID        uint       `gorm:"primarykey" json:"id"`
+    UserID    uint       `gorm:"index;not null" json:"user_id"`
+    TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+    ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+    UsedAt    *time.Time `json:"used_at"`
+    IPAddress string     `json:"ip_address"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+// EmailVerificationToken lưu hash của token xác thực email khi khách hàng đăng ký
+type EmailVerificationToken struct {
+    ID        uint       `gorm:"primarykey" json:"id"`
+    UserID    uint       `gorm:"index;not null" json:"user_id"`
+    TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+    ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+    UsedAt    *time.Time `json:"used_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
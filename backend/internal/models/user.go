@@ -25,9 +25,13 @@ type User struct {
     CreatedAt         time.Time      `json:"created_at"`
     UpdatedAt         time.Time      `json:"updated_at"`
     LastLogin         *time.Time     `json:"last_login"`
+    EmailVerifiedAt   *time.Time     `json:"email_verified_at"`
     FailedLoginCount  int            `gorm:"default:0" json:"-"`
     LastFailedLogin   *time.Time     `json:"-"`
     LockedUntil       *time.Time     `json:"-"`
+    TOTPSecret        string         `json:"-"`
+    TOTPEnabled       bool           `gorm:"default:false" json:"totp_enabled"`
+    TOTPLastCounter   int64          `gorm:"default:0" json:"-"`
     DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
     Profile           UserProfile    `gorm:"foreignKey:UserID" json:"profile,omitempty"`
 }
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore lưu trạng thái trong bộ nhớ của process, chỉ dùng được khi chạy một replica
+type MemoryStore struct {
+	mu   sync.Mutex
+	tats map[string]time.Time
+}
+
+// NewMemoryStore tạo store trong bộ nhớ
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tats: make(map[string]time.Time)}
+}
+
+// Allow ghi nhận một request của key
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, tat := gcra(time.Now(), s.tats[key], limit, window)
+	s.tats[key] = tat
+	return result, nil
+}
+
+// Cleanup định kỳ xóa các key đã phục hồi hoàn toàn cho tới khi ctx bị hủy
+func (s *MemoryStore) Cleanup(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		now := time.Now()
+		for key, tat := range s.tats {
+			if !tat.After(now) {
+				delete(s.tats, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
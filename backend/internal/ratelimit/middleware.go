@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/tastygo/internal/logging"
+	"github.com/yourusername/tastygo/internal/metrics"
+)
+
+// Tên các nhóm route được cấu hình giới hạn riêng
+const (
+	// PolicyLogin áp dụng cho đăng nhập và xác thực 2FA
+	PolicyLogin = "login"
+	// PolicyAuth áp dụng cho các route công khai khác của auth (đăng ký, quên mật khẩu...)
+	PolicyAuth = "auth"
+	// PolicyRead áp dụng cho request GET của route cần đăng nhập
+	PolicyRead = "read"
+	// PolicyWrite áp dụng cho request thay đổi dữ liệu và làm mới token
+	PolicyWrite = "write"
+	// PolicyAPI áp dụng theo IP cho mọi request tới route cần đăng nhập, trước khi xác thực,
+	// để request mang token, cookie hay API key không hợp lệ cũng bị giới hạn
+	PolicyAPI = "api"
+)
+
+// Limiter áp dụng các policy lên route. Limiter nil không giới hạn gì, để router
+// dùng được khi rate limit bị tắt
+type Limiter struct {
+	store    Store
+	policies map[string]Policy
+}
+
+// New tạo Limiter với store và các policy theo tên
+func New(store Store, policies ...Policy) *Limiter {
+	l := &Limiter{store: store, policies: make(map[string]Policy, len(policies))}
+	for _, policy := range policies {
+		l.policies[policy.Name] = policy
+	}
+	return l
+}
+
+// Policy trả về middleware giới hạn theo policy có tên name
+func (l *Limiter) Policy(name string) gin.HandlerFunc {
+	if l == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	policy := l.policy(name)
+	return func(c *gin.Context) {
+		l.limit(c, policy)
+	}
+}
+
+// ByMethod trả về middleware dùng policy read cho GET/HEAD và policy write cho các method khác.
+// Phải đặt sau AuthMiddleware để giới hạn được theo user
+func (l *Limiter) ByMethod(read, write string) gin.HandlerFunc {
+	if l == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	readPolicy, writePolicy := l.policy(read), l.policy(write)
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			l.limit(c, readPolicy)
+			return
+		}
+		l.limit(c, writePolicy)
+	}
+}
+
+// policy tìm policy theo tên; thiếu policy là lỗi cấu hình route nên panic ngay lúc khởi động
+func (l *Limiter) policy(name string) Policy {
+	policy, ok := l.policies[name]
+	if !ok {
+		panic(fmt.Sprintf("ratelimit: unknown policy %q", name))
+	}
+	return policy
+}
+
+// limit kiểm tra request theo policy, gắn header RateLimit-* và từ chối nếu vượt giới hạn
+func (l *Limiter) limit(c *gin.Context, policy Policy) {
+	if !policy.Enabled() {
+		c.Next()
+		return
+	}
+
+	result, err := l.store.Allow(c.Request.Context(), policy.Name+":"+clientKey(c, policy.Key), policy.Limit, policy.Window)
+	if err != nil {
+		// Store lỗi (ví dụ Redis không kết nối được): cho qua thay vì chặn toàn bộ traffic
+		logging.FromContext(c.Request.Context()).Warn("Rate limit store unavailable", map[string]interface{}{
+			"policy": policy.Name,
+			"error":  err.Error(),
+		})
+		c.Next()
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(seconds(result.ResetAfter)))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, seconds(policy.Window)))
+
+	if !result.Allowed {
+		header.Set("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+		metrics.RateLimitRejections.WithLabelValues(c.FullPath()).Inc()
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":   "rate limit exceeded",
+			"message": "too many requests, please try again later",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// clientKey xác định client theo kind, lùi về user rồi IP khi không có thông tin.
+// API key được lấy từ context do AuthMiddleware đặt, nên key chỉ được tính khi đã xác thực
+func clientKey(c *gin.Context, kind KeyKind) string {
+	if kind == KeyAPIKey {
+		if keyID, ok := c.Get("api_key_id"); ok {
+			return fmt.Sprintf("api_key:%v", keyID)
+		}
+	}
+	if kind == KeyUser || kind == KeyAPIKey {
+		if userID, ok := c.Get("user_id"); ok {
+			return fmt.Sprintf("user:%v", userID)
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// seconds làm tròn lên thời gian theo giây cho header
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyKind là cách xác định client khi đếm request
+type KeyKind string
+
+const (
+	// KeyIP đếm theo IP của client
+	KeyIP KeyKind = "ip"
+	// KeyUser đếm theo user đã đăng nhập, dùng IP nếu request chưa xác thực
+	KeyUser KeyKind = "user"
+	// KeyAPIKey đếm theo API key đã xác thực, dùng user rồi IP nếu request không dùng API key
+	KeyAPIKey KeyKind = "api_key"
+)
+
+// Policy là giới hạn cho một nhóm route: tối đa Limit request trong mỗi Window cho mỗi key.
+// Request được phân bổ đều theo thuật toán GCRA (tương đương token bucket với burst = Limit)
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	Key    KeyKind
+}
+
+// Enabled cho biết policy có giới hạn hay không; Limit <= 0 là không giới hạn
+func (p Policy) Enabled() bool {
+	return p.Limit > 0 && p.Window > 0
+}
+
+// String trả về policy dạng "limit/window:key", cùng định dạng với ParsePolicy
+func (p Policy) String() string {
+	return fmt.Sprintf("%d/%s:%s", p.Limit, p.Window, p.Key)
+}
+
+// ParsePolicy đọc policy dạng "limit/window[:key]", ví dụ "10/1m:ip" hoặc "300/1m:user".
+// Key mặc định là ip; "0" hoặc "off" là không giới hạn
+func ParsePolicy(name, spec string) (Policy, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "0" || spec == "off" {
+		return Policy{Name: name, Key: KeyIP}, nil
+	}
+
+	rateSpec, key, _ := strings.Cut(spec, ":")
+	policy := Policy{Name: name, Key: KeyIP}
+	if key != "" {
+		switch kind := KeyKind(strings.ToLower(key)); kind {
+		case KeyIP, KeyUser, KeyAPIKey:
+			policy.Key = kind
+		default:
+			return Policy{}, fmt.Errorf("rate limit %s: unknown key %q", name, key)
+		}
+	}
+
+	limitSpec, windowSpec, ok := strings.Cut(rateSpec, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("rate limit %s: expected limit/window, got %q", name, spec)
+	}
+	limit, err := strconv.Atoi(limitSpec)
+	if err != nil || limit < 0 {
+		return Policy{}, fmt.Errorf("rate limit %s: invalid limit %q", name, limitSpec)
+	}
+	window, err := time.ParseDuration(windowSpec)
+	if err != nil || window <= 0 {
+		return Policy{}, fmt.Errorf("rate limit %s: invalid window %q", name, windowSpec)
+	}
+
+	policy.Limit = limit
+	policy.Window = window
+	return policy, nil
+}
+
+// Result là kết quả kiểm tra một request
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining là số request còn được phép ngay lúc này
+	Remaining int
+	// ResetAfter là thời gian tới khi key được phục hồi đủ Limit request
+	ResetAfter time.Duration
+	// RetryAfter là thời gian phải chờ trước khi thử lại khi bị từ chối
+	RetryAfter time.Duration
+}
+
+// Store lưu trạng thái giới hạn của các key. Store dùng chung (Redis) giúp giới hạn
+// có hiệu lực trên mọi replica
+type Store interface {
+	// Allow ghi nhận một request của key và trả về kết quả theo limit/window
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+}
+
+// gcra tính kết quả theo Generic Cell Rate Algorithm. tat (theoretical arrival time) là
+// thời điểm key được phục hồi hoàn toàn; trả về tat mới nếu request được chấp nhận
+func gcra(now, tat time.Time, limit int, window time.Duration) (Result, time.Time) {
+	interval := window / time.Duration(limit)
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-window)
+	if now.Before(allowAt) {
+		return Result{
+			Limit:      limit,
+			ResetAfter: tat.Sub(now),
+			RetryAfter: allowAt.Sub(now),
+		}, tat
+	}
+
+	resetAfter := newTAT.Sub(now)
+	return Result{
+		Allowed:    true,
+		Limit:      limit,
+		Remaining:  int((window - resetAfter) / interval),
+		ResetAfter: resetAfter,
+	}, newTAT
+}
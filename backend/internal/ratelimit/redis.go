@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// gcraScript cài đặt GCRA nguyên tử trong Redis. Thời gian tính bằng microsecond và do
+// client truyền vào; key hết hạn khi đã phục hồi hoàn toàn nên không cần dọn dẹp
+var gcraScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local window = tonumber(ARGV[3])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - window
+if now < allow_at then
+	return {0, tat - now, allow_at - now}
+end
+
+redis.call('SET', KEYS[1], string.format('%.0f', new_tat), 'PX', math.ceil((new_tat - now) / 1000))
+return {1, new_tat - now, 0}
+`)
+
+// RedisStore lưu trạng thái trong Redis để mọi replica dùng chung giới hạn
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore kết nối tới Redis tại addr. Mọi key được thêm prefix
+func NewRedisStore(addr, password string, db int, prefix string) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &RedisStore{client: client, prefix: prefix}, nil
+}
+
+// Allow ghi nhận một request của key
+func (s *RedisStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	interval := window / time.Duration(limit)
+	values, err := gcraScript.Run(ctx, s.client, []string{s.prefix + key},
+		time.Now().UnixMicro(), interval.Microseconds(), window.Microseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		ResetAfter: time.Duration(values[1]) * time.Microsecond,
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+	}
+	if result.Allowed {
+		result.Remaining = int((window - result.ResetAfter) / interval)
+	}
+	return result, nil
+}
+
+// Name trả về tên dùng trong báo cáo /readyz
+func (s *RedisStore) Name() string {
+	return "rate_limit_store"
+}
+
+// Check ping Redis
+func (s *RedisStore) Check(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// Close đóng kết nối tới Redis
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/pagination"
+	"gorm.io/gorm"
+)
+
+type gormActivityLogRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormActivityLogRepository) Create(log *models.ActivityLog) error {
+	return r.db.Create(log).Error
+}
+
+func (r *gormActivityLogRepository) List(filter ActivityLogFilter, params pagination.Params) ([]models.ActivityLog, int64, error) {
+	query := r.db.Model(&models.ActivityLog{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.ActivityLog
+	err := pagination.Apply(query.Order("created_at DESC, id DESC"), params).Find(&logs).Error
+	return logs, total, err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/yourusername/tastygo/internal/models"
+	"gorm.io/gorm"
+)
+
+type gormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormAPIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *gormAPIKeyRepository) FindByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Preload("Permissions").First(&key, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &key, nil
+}
+
+func (r *gormAPIKeyRepository) FindByPrefix(prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Preload("Permissions").Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &key, nil
+}
+
+func (r *gormAPIKeyRepository) List(filter APIKeyFilter) ([]models.APIKey, error) {
+	query := r.db.Preload("Permissions")
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if !filter.IncludeRevoked {
+		query = query.Where("revoked_at IS NULL")
+	}
+
+	var keys []models.APIKey
+	err := query.Order("created_at DESC, id DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke thu hồi key; điều kiện revoked_at IS NULL giữ nguyên thời điểm thu hồi lần đầu
+func (r *gormAPIKeyRepository) Revoke(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// TouchLastUsed ghi thời điểm và IP sử dụng gần nhất nếu lần ghi trước cũ hơn staleBefore hoặc
+// IP đã thay đổi. Trả về true khi có cập nhật, nhờ vậy nhiều request đồng thời chỉ ghi một lần
+func (r *gormAPIKeyRepository) TouchLastUsed(id uint, ip string, at, staleBefore time.Time) (bool, error) {
+	result := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ? OR last_used_ip <> ?)", id, staleBefore, ip).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ip})
+	return result.RowsAffected > 0, result.Error
+}
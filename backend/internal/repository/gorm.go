@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NewGormRepositories tạo bộ repository dùng GORM
+func NewGormRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:        &gormUserRepository{db: db},
+		Sessions:     &gormSessionRepository{db: db},
+		ActivityLogs: &gormActivityLogRepository{db: db},
+		Roles:        &gormRoleRepository{db: db},
+		MFA:          &gormMFARepository{db: db},
+		Tokens:       &gormTokenRepository{db: db},
+		APIKeys:      &gormAPIKeyRepository{db: db},
+		Tx:           gormTransactor{db: db},
+	}
+}
+
+type gormTransactor struct {
+	db *gorm.DB
+}
+
+func (t gormTransactor) Transaction(fn func(tx Repositories) error) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormRepositories(tx))
+	})
+}
+
+// IsUniqueViolation nhận diện lỗi vi phạm unique index từ database
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return errors.Is(err, gorm.ErrDuplicatedKey) ||
+		strings.Contains(msg, "unique constraint") ||
+		strings.Contains(msg, "duplicate key")
+}
+
+// translateError chuyển lỗi "không tìm thấy" của GORM sang ErrNotFound
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
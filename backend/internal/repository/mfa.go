@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/yourusername/tastygo/internal/models"
+	"gorm.io/gorm"
+)
+
+type gormMFARepository struct {
+	db *gorm.DB
+}
+
+// ReplaceRecoveryCodes xóa mã khôi phục cũ và lưu bộ hash mới
+func (r *gormMFARepository) ReplaceRecoveryCodes(userID uint, hashes []string) error {
+	if err := r.DeleteRecoveryCodes(userID); err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		code := models.RecoveryCode{UserID: userID, CodeHash: hash}
+		if err := r.db.Create(&code).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *gormMFARepository) DeleteRecoveryCodes(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
+
+// UseRecoveryCode đánh dấu mã đã dùng, trả về false nếu mã không tồn tại hoặc đã dùng
+func (r *gormMFARepository) UseRecoveryCode(userID uint, hash string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *gormMFARepository) FindPolicy(role models.Role) (*models.MFAPolicy, error) {
+	var policy models.MFAPolicy
+	if err := r.db.Where("role = ?", role).First(&policy).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &policy, nil
+}
+
+func (r *gormMFARepository) ListPolicies() ([]models.MFAPolicy, error) {
+	var policies []models.MFAPolicy
+	err := r.db.Order("role").Find(&policies).Error
+	return policies, err
+}
+
+func (r *gormMFARepository) SavePolicy(policy *models.MFAPolicy) error {
+	return r.db.Save(policy).Error
+}
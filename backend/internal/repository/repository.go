@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/pagination"
+)
+
+// ErrNotFound được trả về khi không tìm thấy bản ghi
+var ErrNotFound = errors.New("record not found")
+
+// UserFilter là các điều kiện lọc khi liệt kê user
+type UserFilter struct {
+	Role    models.Role
+	Active  *bool
+	Locked  *bool
+	Deleted bool
+	Search  string
+}
+
+// ActivityLogFilter là các điều kiện lọc khi liệt kê activity log
+type ActivityLogFilter struct {
+	UserID *uint
+}
+
+// APIKeyFilter là các điều kiện lọc khi liệt kê API key
+type APIKeyFilter struct {
+	UserID         *uint
+	IncludeRevoked bool
+}
+
+// UserRepository truy cập dữ liệu user và profile
+type UserRepository interface {
+	FindByID(id uint) (*models.User, error)
+	FindByIDUnscoped(id uint) (*models.User, error)
+	// FindByEmail và EmailExists so khớp không phân biệt hoa thường, kể cả với email cũ
+	// được lưu trước khi chuẩn hóa
+	FindByEmail(email string) (*models.User, error)
+	EmailExists(email string, excludeID uint) (bool, error)
+	UsernameExists(username string, excludeID uint) (bool, error)
+	CountByRole(role models.Role) (int64, error)
+	List(filter UserFilter, params pagination.Params) ([]models.User, int64, error)
+	Create(user *models.User) error
+	Save(user *models.User) error
+	UpdateFields(id uint, fields map[string]interface{}) error
+	SaveProfile(profile *models.UserProfile) error
+	Delete(id uint) error
+	Restore(id uint) error
+}
+
+// SessionRepository truy cập dữ liệu phiên đăng nhập (access/refresh token)
+type SessionRepository interface {
+	Create(session *models.Session) error
+	FindValidByToken(token string, now time.Time) (*models.Session, error)
+	FindByToken(token string) (*models.Session, error)
+	FindByRefreshHash(hash string) (*models.Session, error)
+	ListActive(userID uint, now time.Time) ([]models.Session, error)
+	CountActive(now time.Time) (int64, error)
+	MarkRotated(id uint, at time.Time) (bool, error)
+	RevokeFamily(familyID string, at time.Time) error
+	RevokeUserFamily(userID uint, familyID string, at time.Time) (int64, error)
+	RevokeUserSessions(userID uint, exceptFamilyID string, at time.Time) (int64, error)
+}
+
+// ActivityLogRepository truy cập dữ liệu activity log
+type ActivityLogRepository interface {
+	Create(log *models.ActivityLog) error
+	List(filter ActivityLogFilter, params pagination.Params) ([]models.ActivityLog, int64, error)
+}
+
+// RoleRepository truy cập định nghĩa role và tập quyền
+type RoleRepository interface {
+	Permissions(role models.Role) ([]models.Permission, error)
+	Exists(role models.Role) (bool, error)
+	List() ([]models.RoleDefinition, error)
+	Find(name models.Role) (*models.RoleDefinition, error)
+	Create(role *models.RoleDefinition) error
+	ReplacePermissions(role *models.RoleDefinition) error
+	Delete(name models.Role) error
+}
+
+// MFARepository truy cập mã khôi phục và chính sách 2FA
+type MFARepository interface {
+	ReplaceRecoveryCodes(userID uint, hashes []string) error
+	DeleteRecoveryCodes(userID uint) error
+	UseRecoveryCode(userID uint, hash string, at time.Time) (bool, error)
+	FindPolicy(role models.Role) (*models.MFAPolicy, error)
+	ListPolicies() ([]models.MFAPolicy, error)
+	SavePolicy(policy *models.MFAPolicy) error
+}
+
+// TokenRepository truy cập các token gửi qua email (đặt lại mật khẩu, xác thực email)
+type TokenRepository interface {
+	CreatePasswordReset(token *models.PasswordResetToken) error
+	InvalidatePasswordResets(userID uint, at time.Time) error
+	FindValidPasswordReset(hash string, now time.Time) (*models.PasswordResetToken, error)
+	UsePasswordReset(id uint, at time.Time) (bool, error)
+	CreateEmailVerification(token *models.EmailVerificationToken) error
+	InvalidateEmailVerifications(userID uint, at time.Time) error
+	FindValidEmailVerification(hash string, now time.Time) (*models.EmailVerificationToken, error)
+	UseEmailVerification(id uint, at time.Time) (bool, error)
+}
+
+// APIKeyRepository truy cập API key và tập quyền của key
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByID(id uint) (*models.APIKey, error)
+	FindByPrefix(prefix string) (*models.APIKey, error)
+	List(filter APIKeyFilter) ([]models.APIKey, error)
+	Revoke(id uint, at time.Time) (bool, error)
+	TouchLastUsed(id uint, ip string, at, staleBefore time.Time) (bool, error)
+}
+
+// Transactor chạy một hàm trong transaction với bộ repository gắn với transaction đó
+type Transactor interface {
+	Transaction(fn func(tx Repositories) error) error
+}
+
+// Repositories gom tất cả repository dùng chung một kết nối database
+type Repositories struct {
+	Users        UserRepository
+	Sessions     SessionRepository
+	ActivityLogs ActivityLogRepository
+	Roles        RoleRepository
+	MFA          MFARepository
+	Tokens       TokenRepository
+	APIKeys      APIKeyRepository
+	Tx           Transactor
+}
+
+// Transaction chạy fn trong transaction. Nếu không có Transactor (ví dụ khi dùng fake
+// trong test), fn được gọi trực tiếp với chính bộ repository hiện tại.
+func (r Repositories) Transaction(fn func(tx Repositories) error) error {
+	if r.Tx == nil {
+		return fn(r)
+	}
+	return r.Tx.Transaction(fn)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/yourusername/tastygo/internal/models"
+	"gorm.io/gorm"
+)
+
+type gormRoleRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormRoleRepository) Permissions(role models.Role) ([]models.Permission, error) {
+	var rows []models.RolePermission
+	if err := r.db.Where("role = ?", role).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	permissions := make([]models.Permission, 0, len(rows))
+	for _, row := range rows {
+		permissions = append(permissions, row.Permission)
+	}
+	return permissions, nil
+}
+
+func (r *gormRoleRepository) Exists(role models.Role) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RoleDefinition{}).Where("name = ?", role).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormRoleRepository) List() ([]models.RoleDefinition, error) {
+	var roles []models.RoleDefinition
+	err := r.db.Preload("Permissions").Order("name").Find(&roles).Error
+	return roles, err
+}
+
+func (r *gormRoleRepository) Find(name models.Role) (*models.RoleDefinition, error) {
+	var role models.RoleDefinition
+	if err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &role, nil
+}
+
+func (r *gormRoleRepository) Create(role *models.RoleDefinition) error {
+	return r.db.Create(role).Error
+}
+
+// ReplacePermissions lưu mô tả và thay thế toàn bộ tập quyền của role
+func (r *gormRoleRepository) ReplacePermissions(role *models.RoleDefinition) error {
+	if err := r.db.Where("role = ?", role.Name).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	return r.db.Save(role).Error
+}
+
+func (r *gormRoleRepository) Delete(name models.Role) error {
+	if err := r.db.Where("role = ?", name).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("name = ?", name).Delete(&models.RoleDefinition{}).Error
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/yourusername/tastygo/internal/models"
+	"gorm.io/gorm"
+)
+
+type gormSessionRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormSessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+// FindValidByToken tìm session còn hạn, chưa bị rotate hoặc thu hồi theo access token
+func (r *gormSessionRepository) FindValidByToken(token string, now time.Time) (*models.Session, error) {
+	var session models.Session
+	err := r.db.Where("token = ? AND expires_at > ? AND revoked_at IS NULL AND rotated_at IS NULL", token, now).First(&session).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &session, nil
+}
+
+func (r *gormSessionRepository) FindByToken(token string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.Where("token = ?", token).First(&session).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &session, nil
+}
+
+func (r *gormSessionRepository) FindByRefreshHash(hash string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.Where("refresh_token_hash = ?", hash).First(&session).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &session, nil
+}
+
+func (r *gormSessionRepository) ListActive(userID uint, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND rotated_at IS NULL AND refresh_expires_at > ?", userID, now).
+		Order("created_at DESC, id DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// CountActive đếm số session của mọi user có refresh token còn hiệu lực
+func (r *gormSessionRepository) CountActive(now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Session{}).
+		Where("revoked_at IS NULL AND rotated_at IS NULL AND refresh_expires_at > ?", now).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRotated đánh dấu session đã được rotate. Điều kiện rotated_at IS NULL đảm bảo
+// hai request refresh đồng thời không thể cùng dùng một token.
+func (r *gormSessionRepository) MarkRotated(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", id).
+		Update("rotated_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormSessionRepository) RevokeFamily(familyID string, at time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+func (r *gormSessionRepository) RevokeUserFamily(userID uint, familyID string, at time.Time) (int64, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL AND rotated_at IS NULL", userID, familyID).
+		Update("revoked_at", at)
+	return result.RowsAffected, result.Error
+}
+
+// RevokeUserSessions thu hồi mọi session của user, trừ family exceptFamilyID (nếu khác rỗng)
+func (r *gormSessionRepository) RevokeUserSessions(userID uint, exceptFamilyID string, at time.Time) (int64, error) {
+	query := r.db.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL AND rotated_at IS NULL", userID)
+	if exceptFamilyID != "" {
+		query = query.Where("family_id <> ?", exceptFamilyID)
+	}
+	result := query.Update("revoked_at", at)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/yourusername/tastygo/internal/models"
+	"gorm.io/gorm"
+)
+
+type gormTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTokenRepository) CreatePasswordReset(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+// InvalidatePasswordResets vô hiệu hóa các token đặt lại mật khẩu chưa dùng của user
+func (r *gormTokenRepository) InvalidatePasswordResets(userID uint, at time.Time) error {
+	return r.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+}
+
+func (r *gormTokenRepository) FindValidPasswordReset(hash string, now time.Time) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hash, now).First(&token).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &token, nil
+}
+
+// UsePasswordReset đánh dấu token đã dùng; điều kiện used_at IS NULL đảm bảo chỉ dùng được một lần
+func (r *gormTokenRepository) UsePasswordReset(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormTokenRepository) CreateEmailVerification(token *models.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+// InvalidateEmailVerifications vô hiệu hóa các token xác thực email chưa dùng của user
+func (r *gormTokenRepository) InvalidateEmailVerifications(userID uint, at time.Time) error {
+	return r.db.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+}
+
+func (r *gormTokenRepository) FindValidEmailVerification(hash string, now time.Time) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	err := r.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hash, now).First(&token).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &token, nil
+}
+
+// UseEmailVerification đánh dấu token đã dùng; điều kiện used_at IS NULL đảm bảo chỉ dùng được một lần
+func (r *gormTokenRepository) UseEmailVerification(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
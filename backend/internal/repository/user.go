@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/pagination"
+	"gorm.io/gorm"
+)
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.Preload("Profile").First(&user, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByIDUnscoped(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.Unscoped().Preload("Profile").First(&user, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+// EmailExists kiểm tra trùng email, kể cả user đã bị xóa mềm (unique index vẫn áp dụng)
+func (r *gormUserRepository) EmailExists(email string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.User{}).Where("LOWER(email) = LOWER(?) AND id <> ?", email, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// UsernameExists kiểm tra trùng username, kể cả user đã bị xóa mềm
+func (r *gormUserRepository) UsernameExists(username string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.User{}).Where("username = ? AND id <> ?", username, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormUserRepository) CountByRole(role models.Role) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
+
+func (r *gormUserRepository) List(filter UserFilter, params pagination.Params) ([]models.User, int64, error) {
+	query := r.db.Model(&models.User{})
+	if filter.Deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
+	}
+	if filter.Locked != nil {
+		if *filter.Locked {
+			query = query.Where("locked_until > ?", time.Now())
+		} else {
+			query = query.Where("locked_until IS NULL OR locked_until <= ?", time.Now())
+		}
+	}
+	if filter.Search != "" {
+		term := "%" + strings.ToLower(filter.Search) + "%"
+		query = query.Where(
+			"LOWER(email) LIKE ? OR LOWER(username) LIKE ? OR id IN (SELECT user_id FROM user_profiles WHERE LOWER(full_name) LIKE ?)",
+			term, term, term,
+		)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := pagination.Apply(query, params).Preload("Profile").Order("id").Find(&users).Error
+	return users, total, err
+}
+
+func (r *gormUserRepository) Create(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+// Save lưu các trường của user, profile được lưu riêng qua SaveProfile
+func (r *gormUserRepository) Save(user *models.User) error {
+	return r.db.Omit("Profile").Save(user).Error
+}
+
+func (r *gormUserRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *gormUserRepository) SaveProfile(profile *models.UserProfile) error {
+	return r.db.Save(profile).Error
+}
+
+func (r *gormUserRepository) Delete(id uint) error {
+	return r.db.Delete(&models.User{}, id).Error
+}
+
+func (r *gormUserRepository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&models.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
@@ -0,0 +1,13 @@
+// Package migrations chứa các file SQL migration được nhúng vào binary.
+//
+// Mỗi database driver có thư mục riêng (sqlite/, postgres/) với cùng danh sách phiên bản.
+// Mỗi migration gồm hai file: <version>_<name>.up.sql và <version>_<name>.down.sql.
+// Không sửa migration đã được áp dụng, hãy tạo migration mới bằng `tastygo migrate create <name>`.
+package migrations
+
+import "embed"
+
+// FS chứa toàn bộ file migration, phân theo thư mục của từng driver
+//
+//go:embed sqlite/*.sql postgres/*.sql
+var FS embed.FS
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS activity_logs;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS user_profiles;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,22 @@
+DROP TABLE IF EXISTS email_verification_tokens;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS role_definitions;
+DROP TABLE IF EXISTS mfa_policies;
+DROP TABLE IF EXISTS recovery_codes;
+
+ALTER TABLE activity_logs DROP COLUMN IF EXISTS changes;
+
+DROP INDEX IF EXISTS idx_sessions_family_id;
+DROP INDEX IF EXISTS idx_sessions_refresh_token_hash;
+ALTER TABLE sessions DROP COLUMN IF EXISTS revoked_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS rotated_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS refresh_expires_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS refresh_token_hash;
+ALTER TABLE sessions DROP COLUMN IF EXISTS login_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS family_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_counter;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
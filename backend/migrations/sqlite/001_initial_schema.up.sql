@@ -0,0 +1,60 @@
+-- Schema ban đầu, trùng khớp với schema mà AutoMigrate của phiên bản trước tạo ra để
+-- database có sẵn được tiếp nhận mà không thay đổi gì. Các cột và bảng mới nằm ở migration sau.
+
+-- Tạo bảng users
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY,
+    email TEXT NOT NULL,
+    username TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL,
+    active NUMERIC DEFAULT TRUE,
+    created_at DATETIME,
+    updated_at DATETIME,
+    last_login DATETIME,
+    failed_login_count INTEGER DEFAULT 0,
+    last_failed_login DATETIME,
+    locked_until DATETIME,
+    deleted_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(username);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+
+-- Tạo bảng user_profiles
+CREATE TABLE IF NOT EXISTS user_profiles (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    full_name TEXT,
+    phone TEXT,
+    address TEXT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    CONSTRAINT fk_users_profile FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_profiles_user_id ON user_profiles(user_id);
+
+-- Tạo bảng sessions
+CREATE TABLE IF NOT EXISTS sessions (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    token TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME,
+    ip_address TEXT,
+    user_agent TEXT
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_token ON sessions(token);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+-- Tạo bảng activity_logs
+CREATE TABLE IF NOT EXISTS activity_logs (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    activity_type TEXT NOT NULL,
+    description TEXT,
+    ip_address TEXT,
+    user_agent TEXT,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_activity_logs_user_id ON activity_logs(user_id);
@@ -1,28 +1,38 @@
 package main
 
 import (
+    "errors"
     "log"
-    "os"
 
+    "github.com/yourusername/tastygo/config"
     "github.com/yourusername/tastygo/internal/database"
     "github.com/yourusername/tastygo/internal/models"
 )
 
 func main() {
     // Khởi tạo database
-    dbPath := os.Getenv("DB_PATH")
-    if dbPath == "" {
-        dbPath = "tastygo.db"
+    // Seed chỉ cần cấu hình database nên bỏ qua lỗi validate của các phần khác
+    cfg, err := config.Load(nil)
+    var invalid *config.ValidationError
+    if err != nil && !errors.As(err, &invalid) {
+        log.Fatalf("Failed to load configuration: %v", err)
     }
-
-    err := database.InitDB(dbPath)
+    db, err := database.InitDB(database.Config{
+        Driver:          cfg.Database.Driver,
+        DSN:             cfg.Database.DSN,
+        MaxOpenConns:    cfg.Database.MaxOpenConns,
+        MaxIdleConns:    cfg.Database.MaxIdleConns,
+        ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+        ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+        Production:      cfg.App.Release(),
+    })
     if err != nil {
         log.Fatalf("Failed to initialize database: %v", err)
     }
 
     // Tạo SuperAdmin nếu chưa có
     var count int64
-    database.DB.Model(&models.User{}).Where("role = ?", models.RoleSuperAdmin).Count(&count)
+    db.Model(&models.User{}).Where("role = ?", models.RoleSuperAdmin).Count(&count)
     
     if count == 0 {
         superAdmin := models.User{
@@ -40,7 +50,7 @@ func main() {
             log.Fatalf("Failed to set password: %v", err)
         }
         
-        result := database.DB.Create(&superAdmin)
+        result := db.Create(&superAdmin)
         if result.Error != nil {
             log.Fatalf("Failed to create superadmin: %v", result.Error)
         }
@@ -49,7 +59,7 @@ func main() {
     }
 
     // Tạo Admin test nếu chưa có
-    database.DB.Model(&models.User{}).Where("email = ?", "testadmin@tastygo.com").Count(&count)
+    db.Model(&models.User{}).Where("email = ?", "testadmin@tastygo.com").Count(&count)
     
     if count == 0 {
         admin := models.User{
@@ -68,7 +78,7 @@ func main() {
             log.Fatalf("Failed to set password: %v", err)
         }
         
-        result := database.DB.Create(&admin)
+        result := db.Create(&admin)
         if result.Error != nil {
             log.Fatalf("Failed to create test admin: %v", result.Error)
         }
@@ -12,7 +12,7 @@ import (
 
 func TestLoginEndpoint(t *testing.T) {
     // Khởi tạo server
-    router := api.NewServer()
+    router := api.NewServer(testService)
     
     // Tạo request đăng nhập
     loginData := map[string]string{
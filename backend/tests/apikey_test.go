@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+// partnerAddr là địa chỉ client của partner trong test
+const partnerAddr = "203.0.113.7:40000"
+
+// doAPIKey gửi request từ partnerAddr, xác thực bằng API key qua header Authorization
+func doAPIKey(router http.Handler, method, path, key string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = partnerAddr
+	req.Header.Set("Authorization", "ApiKey "+key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// createPartner tạo user thuộc role có quyền dashboard.view và users.read để làm chủ API key
+func createPartner(t *testing.T, email string, role models.Role) models.User {
+	t.Helper()
+
+	_, err := testService.CreateRole(role, "Partner integration", []models.Permission{
+		models.PermDashboardView,
+		models.PermUsersRead,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return createUser(t, email, role, "Secret#123")
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	router := api.NewServer(testService)
+	adminToken := loginSuperAdmin(t, router)["token"].(string)
+	owner := createPartner(t, "partner-key@tastygo.com", "partner_lifecycle")
+
+	w := doJSON(router, "POST", "/api/admin/api-keys", map[string]interface{}{
+		"name":        "Partner sync",
+		"user_id":     owner.ID,
+		"permissions": []string{"users.read"},
+	}, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	created := decode(w)
+	key, _ := created["key"].(string)
+	if key == "" || created["prefix"] == "" {
+		t.Fatalf("Expected key and prefix in response, got %v", created)
+	}
+
+	// Key chỉ có các quyền được gán dù role của user sở hữu có nhiều quyền hơn
+	if w := doAPIKey(router, "GET", "/api/admin/users", key); w.Code != http.StatusOK {
+		t.Fatalf("Expected granted permission to pass, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doAPIKey(router, "GET", "/api/admin/dashboard", key); w.Code != http.StatusForbidden {
+		t.Errorf("Expected permission outside the key to be rejected, got %d", w.Code)
+	}
+
+	// Header X-API-Key cũng được chấp nhận, request thực hiện thay mặt user sở hữu
+	req, _ := http.NewRequest("GET", "/api/profile", nil)
+	req.RemoteAddr = partnerAddr
+	req.Header.Set("X-API-Key", key)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || decode(w)["email"] != owner.Email {
+		t.Fatalf("Expected profile of key owner, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Route quản lý tài khoản và route giới hạn theo role không chấp nhận API key
+	if w := doAPIKey(router, "GET", "/api/sessions", key); w.Code != http.StatusForbidden {
+		t.Errorf("Expected session-only route to reject API key, got %d", w.Code)
+	}
+	if w := doAPIKey(router, "GET", "/api/admin/api-keys", key); w.Code != http.StatusForbidden {
+		t.Errorf("Expected key management route to reject API key, got %d", w.Code)
+	}
+
+	// Sử dụng key được ghi nhận một lần trong activity log, kèm thời điểm và IP gần nhất
+	var count int64
+	testDB.Model(&models.ActivityLog{}).
+		Where("user_id = ? AND activity_type = ?", owner.ID, models.ActivityAPIKeyUsed).
+		Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 usage log entry, got %d", count)
+	}
+
+	w = doJSON(router, "GET", fmt.Sprintf("/api/admin/api-keys?user_id=%d", owner.ID), nil, adminToken)
+	keys := decode(w)["data"].([]interface{})
+	if len(keys) != 1 {
+		t.Fatalf("Expected 1 key for owner, got %d", len(keys))
+	}
+	listed := keys[0].(map[string]interface{})
+	if listed["last_used_at"] == nil || listed["last_used_ip"] != "203.0.113.7" {
+		t.Errorf("Expected last used timestamp and IP, got %v", listed)
+	}
+	if _, ok := listed["key"]; ok {
+		t.Error("Expected key secret to be returned only on creation")
+	}
+
+	// Key bị thu hồi không dùng được nữa
+	path := fmt.Sprintf("/api/admin/api-keys/%v", created["id"])
+	if w := doJSON(router, "DELETE", path, nil, adminToken); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := doAPIKey(router, "GET", "/api/admin/users", key); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected revoked key to be rejected, got %d", w.Code)
+	}
+	if w := doJSON(router, "DELETE", path, nil, adminToken); w.Code != http.StatusNotFound {
+		t.Errorf("Expected revoking twice to return 404, got %d", w.Code)
+	}
+}
+
+func TestAPIKeyValidation(t *testing.T) {
+	router := api.NewServer(testService)
+	adminToken := loginSuperAdmin(t, router)["token"].(string)
+	owner := createPartner(t, "partner-invalid@tastygo.com", "partner_validation")
+
+	cases := []struct {
+		name        string
+		permissions []string
+		expiresAt   string
+	}{
+		{"outside role", []string{"logs.read"}, ""},
+		{"unknown permission", []string{"orders.delete"}, ""},
+		{"wildcard", []string{"*"}, ""},
+		{"empty", []string{}, ""},
+		{"expired", []string{"users.read"}, time.Now().Add(-time.Hour).Format(time.RFC3339)},
+	}
+	for _, tc := range cases {
+		body := map[string]interface{}{"name": "Invalid", "user_id": owner.ID, "permissions": tc.permissions}
+		if tc.expiresAt != "" {
+			body["expires_at"] = tc.expiresAt
+		}
+		if w := doJSON(router, "POST", "/api/admin/api-keys", body, adminToken); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", tc.name, w.Code, w.Body.String())
+		}
+	}
+
+	// Quản lý API key cần quyền api_keys.manage, role admin mặc định không có
+	createUser(t, "apikey-admin@tastygo.com", models.RoleAdmin, "Secret#123")
+	token := login(t, router, "apikey-admin@tastygo.com", "Secret#123")["token"].(string)
+	body := map[string]interface{}{"name": "Admin key", "user_id": owner.ID, "permissions": []string{"users.read"}}
+	if w := doJSON(router, "POST", "/api/admin/api-keys", body, token); w.Code != http.StatusForbidden {
+		t.Errorf("Expected admin to be rejected, got %d", w.Code)
+	}
+	if _, err := testService.CreateRole("integrations", "Integration manager", []models.Permission{models.PermAPIKeysManage}); err != nil {
+		t.Fatal(err)
+	}
+	createUser(t, "apikey-manager@tastygo.com", "integrations", "Secret#123")
+	managerToken := login(t, router, "apikey-manager@tastygo.com", "Secret#123")["token"].(string)
+	if w := doJSON(router, "GET", "/api/admin/api-keys", nil, managerToken); w.Code != http.StatusOK {
+		t.Errorf("Expected role with api_keys.manage to list keys, got %d", w.Code)
+	}
+
+	w := doJSON(router, "POST", "/api/admin/api-keys", body, adminToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	key := decode(w)["key"].(string)
+
+	// Secret sai và user sở hữu bị vô hiệu hóa đều bị từ chối
+	if w := doAPIKey(router, "GET", "/api/admin/users", key+"x"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected wrong secret to be rejected, got %d", w.Code)
+	}
+	testDB.Model(&models.User{}).Where("id = ?", owner.ID).Update("active", false)
+	if w := doAPIKey(router, "GET", "/api/admin/users", key); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected key of disabled owner to be rejected, got %d", w.Code)
+	}
+}
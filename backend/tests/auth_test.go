@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/tastygo/internal/api"
+)
+
+func TestRefreshTokenRotation(t *testing.T) {
+	router := api.NewServer(testService)
+
+	login := loginSuperAdmin(t, router)
+	refreshToken, _ := login["refresh_token"].(string)
+	if refreshToken == "" {
+		t.Fatal("Expected login response to contain refresh_token")
+	}
+
+	// Lần refresh đầu tiên phải thành công và trả về token mới
+	w := doJSON(router, "POST", "/api/auth/refresh", map[string]string{"refresh_token": refreshToken}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	rotated := decode(w)
+	if rotated["refresh_token"] == refreshToken {
+		t.Error("Expected a new refresh token after rotation")
+	}
+
+	// Access token cũ không còn hợp lệ sau khi rotate
+	if w := doJSON(router, "GET", "/api/profile", nil, login["token"].(string)); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected old access token to be rejected, got %d", w.Code)
+	}
+	if w := doJSON(router, "GET", "/api/profile", nil, rotated["token"].(string)); w.Code != http.StatusOK {
+		t.Errorf("Expected new access token to be accepted, got %d", w.Code)
+	}
+
+	// Dùng lại refresh token cũ => thu hồi cả family
+	w = doJSON(router, "POST", "/api/auth/refresh", map[string]string{"refresh_token": refreshToken}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected reuse to be rejected with %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+	if w := doJSON(router, "GET", "/api/profile", nil, rotated["token"].(string)); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected session family to be revoked after reuse, got %d", w.Code)
+	}
+	w = doJSON(router, "POST", "/api/auth/refresh", map[string]string{"refresh_token": rotated["refresh_token"].(string)}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected rotated refresh token to be revoked, got %d", w.Code)
+	}
+}
+
+func TestSessionManagement(t *testing.T) {
+	router := api.NewServer(testService)
+
+	first := loginSuperAdmin(t, router)
+	second := loginSuperAdmin(t, router)
+	token := second["token"].(string)
+
+	w := doJSON(router, "GET", "/api/sessions", nil, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	sessions := decode(w)["data"].([]interface{})
+	if len(sessions) < 2 {
+		t.Fatalf("Expected at least 2 active sessions, got %d", len(sessions))
+	}
+
+	// Đăng xuất khỏi mọi phiên khác
+	if w := doJSON(router, "DELETE", "/api/sessions", nil, token); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := doJSON(router, "GET", "/api/profile", nil, first["token"].(string)); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected other session to be revoked, got %d", w.Code)
+	}
+
+	w = doJSON(router, "GET", "/api/sessions", nil, token)
+	sessions = decode(w)["data"].([]interface{})
+	if len(sessions) != 1 || sessions[0].(map[string]interface{})["current"] != true {
+		t.Fatalf("Expected only the current session to remain, got %v", sessions)
+	}
+
+	// Thu hồi chính phiên hiện tại
+	id := sessions[0].(map[string]interface{})["id"].(string)
+	if w := doJSON(router, "DELETE", "/api/sessions/"+id, nil, token); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := doJSON(router, "GET", "/api/profile", nil, token); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected revoked session to be rejected, got %d", w.Code)
+	}
+}
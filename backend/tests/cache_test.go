@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/yourusername/tastygo/internal/cache"
+)
+
+type cachedProfile struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := cache.NewMemoryCache(2)
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+
+	// Đọc "a" để "b" trở thành mục ít dùng nhất
+	var value int
+	if !c.Get("a", &value) || value != 1 {
+		t.Fatalf("expected a=1, got %d", value)
+	}
+	c.Set("c", 3, time.Minute)
+
+	if c.Get("b", &value) {
+		t.Fatal("expected b to be evicted")
+	}
+	if !c.Get("a", &value) || !c.Get("c", &value) {
+		t.Fatal("expected a and c to remain")
+	}
+
+	stats := c.Stats()
+	if stats.Entries != 2 || stats.Evictions != 1 || stats.Sets != 3 || stats.Hits != 3 || stats.Misses != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestMemoryCacheExpiration(t *testing.T) {
+	c := cache.NewMemoryCache(0)
+	c.Set("short", "value", 20*time.Millisecond)
+	c.Set("forever", "value", 0)
+
+	time.Sleep(40 * time.Millisecond)
+	var value string
+	if c.Get("short", &value) {
+		t.Fatal("expected expired entry to be missing")
+	}
+	if !c.Get("forever", &value) || value != "value" {
+		t.Fatalf("expected entry without ttl to remain, got %q", value)
+	}
+	if entries := c.Stats().Entries; entries != 1 {
+		t.Fatalf("expected expired entry to be freed, got %d entries", entries)
+	}
+}
+
+// testCacheBackend kiểm tra ngữ nghĩa chung mà mọi backend phải tuân theo
+func testCacheBackend(t *testing.T, c cache.Cache) {
+	t.Helper()
+
+	want := cachedProfile{ID: 42, Email: "cache@example.com"}
+	c.Set("profile_42", want, time.Minute)
+
+	var got cachedProfile
+	if !c.Get("profile_42", &got) || got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if c.Get("missing", &got) {
+		t.Fatal("expected miss for unknown key")
+	}
+
+	c.Set("profile_43", want, time.Minute)
+	c.Delete("profile_42")
+	if c.Get("profile_42", &got) {
+		t.Fatal("expected deleted key to be missing")
+	}
+
+	c.Clear()
+	if c.Get("profile_43", &got) {
+		t.Fatal("expected cache to be empty after clear")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 3 || stats.Sets != 2 || stats.Deletes != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestMemoryCacheBackend(t *testing.T) {
+	c, err := cache.New(cache.Config{Backend: cache.BackendMemory, MaxEntries: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCacheBackend(t, c)
+}
+
+func TestRedisCacheBackend(t *testing.T) {
+	server := miniredis.RunT(t)
+	// Key của ứng dụng khác trong cùng database không bị Clear xóa
+	server.Set("other:key", "keep")
+
+	c, err := cache.New(cache.Config{Backend: cache.BackendRedis, RedisAddr: server.Addr(), Prefix: "tastygo:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(*cache.RedisCache).Close()
+
+	testCacheBackend(t, c)
+
+	if !server.Exists("other:key") {
+		t.Fatal("expected clear to keep keys outside the prefix")
+	}
+
+	// TTL được chuyển cho Redis
+	c.Set("short", "value", time.Second)
+	server.FastForward(2 * time.Second)
+	var value string
+	if c.Get("short", &value) {
+		t.Fatal("expected expired key to be missing")
+	}
+}
+
+func TestUnknownCacheBackend(t *testing.T) {
+	if _, err := cache.New(cache.Config{Backend: "memcached"}); err == nil {
+		t.Fatal("expected error for unsupported backend")
+	}
+}
+
+func TestGetOrLoadDeduplicatesConcurrentLoads(t *testing.T) {
+	loader := cache.NewLoader(cache.NewMemoryCache(0))
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return cachedProfile{ID: 1, Email: "flight@example.com"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]cachedProfile, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := loader.GetOrLoad(context.Background(), "profile_1", &results[i], cache.Options{TTL: time.Minute}, load); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single load, got %d", calls.Load())
+	}
+	for _, result := range results {
+		if result.Email != "flight@example.com" {
+			t.Fatalf("expected every caller to get the loaded value, got %+v", result)
+		}
+	}
+}
+
+func TestGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	loader := cache.NewLoader(cache.NewMemoryCache(0))
+	errLoad := errors.New("database unavailable")
+
+	var value string
+	err := loader.GetOrLoad(context.Background(), "key", &value, cache.Options{TTL: time.Minute}, func(ctx context.Context) (interface{}, error) {
+		return nil, errLoad
+	})
+	if !errors.Is(err, errLoad) {
+		t.Fatalf("expected load error, got %v", err)
+	}
+
+	err = loader.GetOrLoad(context.Background(), "key", &value, cache.Options{TTL: time.Minute}, func(ctx context.Context) (interface{}, error) {
+		return "loaded", nil
+	})
+	if err != nil || value != "loaded" {
+		t.Fatalf("expected retry to load value, got %q (%v)", value, err)
+	}
+}
+
+func TestGetOrLoadServesStaleWhileRevalidating(t *testing.T) {
+	loader := cache.NewLoader(cache.NewMemoryCache(0))
+	opts := cache.Options{TTL: 20 * time.Millisecond, StaleTTL: time.Minute}
+
+	var version atomic.Int32
+	refreshed := make(chan struct{}, 1)
+	load := func(ctx context.Context) (interface{}, error) {
+		v := version.Add(1)
+		if v > 1 {
+			refreshed <- struct{}{}
+		}
+		return v, nil
+	}
+
+	var value int32
+	if err := loader.GetOrLoad(context.Background(), "counter", &value, opts, load); err != nil || value != 1 {
+		t.Fatalf("expected first load to return 1, got %d (%v)", value, err)
+	}
+
+	// Quá TTL: vẫn trả về giá trị cũ ngay và tải lại ở nền
+	time.Sleep(40 * time.Millisecond)
+	if err := loader.GetOrLoad(context.Background(), "counter", &value, opts, load); err != nil || value != 1 {
+		t.Fatalf("expected stale value 1, got %d (%v)", value, err)
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("expected background refresh")
+	}
+
+	// Chờ goroutine nền lưu giá trị mới
+	deadline := time.Now().Add(time.Second)
+	for value != 2 && time.Now().Before(deadline) {
+		loader.GetOrLoad(context.Background(), "counter", &value, opts, load)
+		time.Sleep(5 * time.Millisecond)
+	}
+	if value != 2 {
+		t.Fatalf("expected refreshed value 2, got %d", value)
+	}
+}
+
+func TestGetOrLoadTagInvalidation(t *testing.T) {
+	for name, c := range map[string]func(t *testing.T) cache.Cache{
+		"memory": func(t *testing.T) cache.Cache { return cache.NewMemoryCache(0) },
+		"redis": func(t *testing.T) cache.Cache {
+			c, err := cache.NewRedisCache(miniredis.RunT(t).Addr(), "", 0, "tastygo:")
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { c.Close() })
+			return c
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			loader := cache.NewLoader(c(t))
+
+			var loads atomic.Int32
+			load := func(ctx context.Context) (interface{}, error) {
+				return loads.Add(1), nil
+			}
+			get := func(key string, tags ...string) int32 {
+				var value int32
+				opts := cache.Options{TTL: time.Minute, StaleTTL: time.Minute, Tags: tags}
+				if err := loader.GetOrLoad(context.Background(), key, &value, opts, load); err != nil {
+					t.Fatal(err)
+				}
+				return value
+			}
+
+			profile := get("profile_42", "user:42")
+			permissions := get("permissions_42", "user:42", "role:staff")
+			other := get("profile_43", "user:43")
+			if get("profile_42", "user:42") != profile {
+				t.Fatal("expected cached profile before invalidation")
+			}
+
+			// Tag bị vô hiệu hóa: mọi entry gắn tag được tải lại, kể cả khi còn trong StaleTTL
+			loader.InvalidateTags("user:42")
+			if get("profile_42", "user:42") == profile || get("permissions_42", "user:42", "role:staff") == permissions {
+				t.Fatal("expected entries tagged user:42 to be reloaded")
+			}
+			if get("profile_43", "user:43") != other {
+				t.Fatal("expected entries of other users to stay cached")
+			}
+		})
+	}
+}
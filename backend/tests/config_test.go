@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/tastygo/config"
+)
+
+// writeFile ghi nội dung vào file tạm và trả về đường dẫn
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigDefaultsAreValid(t *testing.T) {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.App.Port != 8080 || cfg.Database.DSN != "tastygo.db" || cfg.RateLimit.Login != "10/1m:ip" {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+}
+
+func TestConfigLayering(t *testing.T) {
+	file := writeFile(t, "tastygo.yaml", `
+app:
+  port: 9000
+  read_timeout: 5s
+database:
+  driver: postgres
+  dsn: postgres://file
+cache:
+  backend: redis
+`)
+
+	// File < biến môi trường < flag
+	t.Setenv("PORT", "9100")
+	t.Setenv("DB_DSN", "postgres://env")
+	cfg, err := config.Load([]string{"--config", file, "--port", "9200"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.App.Port != 9200 {
+		t.Errorf("expected flag to win, got port %d", cfg.App.Port)
+	}
+	if cfg.Database.DSN != "postgres://env" || cfg.Database.Driver != "postgres" {
+		t.Errorf("expected env to override file, got %+v", cfg.Database)
+	}
+	if cfg.App.ReadTimeout != 5*time.Second || cfg.Cache.Backend != "redis" {
+		t.Errorf("expected values from file, got %v %q", cfg.App.ReadTimeout, cfg.Cache.Backend)
+	}
+}
+
+func TestConfigTOMLFile(t *testing.T) {
+	file := writeFile(t, "tastygo.toml", `
+[app]
+port = 9300
+shutdown_delay = "1s"
+
+[rate_limit]
+enabled = false
+`)
+	t.Setenv("CONFIG_FILE", file)
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.App.Port != 9300 || cfg.App.ShutdownDelay != time.Second || cfg.RateLimit.Enabled {
+		t.Errorf("unexpected config from TOML: %+v %+v", cfg.App, cfg.RateLimit)
+	}
+}
+
+func TestConfigValidationListsAllErrors(t *testing.T) {
+	file := writeFile(t, "tastygo.yaml", `
+app:
+  prot: 8080
+`)
+	t.Setenv("PORT", "eighty")
+	t.Setenv("JWT_SECRET", "too-short")
+	t.Setenv("DB_DRIVER", "mysql")
+	t.Setenv("RATE_LIMIT_LOGIN", "lots")
+	t.Setenv("RATE_LIMIT_API", "100/1m:user")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8,proxy.internal")
+
+	_, err := config.Load([]string{"--config", file, "--log-level", "verbose"})
+	var invalid *config.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	for _, want := range []string{"app.prot: unknown key", "PORT: invalid integer", "app.jwt_secret", "database.driver", "log.level", "rate_limit.login", "rate_limit.api", "app.trusted_proxies"} {
+		found := false
+		for _, message := range invalid.Errors {
+			if strings.Contains(message, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected an error mentioning %q, got %v", want, invalid.Errors)
+		}
+	}
+}
+
+func TestValidationErrorFilter(t *testing.T) {
+	file := writeFile(t, "tastygo.yaml", `
+database:
+  driver: mysql
+tls:
+  cert_file: /missing/tls.crt
+`)
+	t.Setenv("PORT", "eighty")
+	t.Setenv("RATE_LIMIT_LOGIN", "lots")
+
+	_, err := config.Load([]string{"--config", file, "--db-max-open-conns", "many"})
+	var invalid *config.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	// Lỗi theo key trong file, flag và lỗi chung của section đều được quy về section
+	database := invalid.Filter("database")
+	if database == nil || len(database.Errors) != 3 || !strings.HasPrefix(database.Errors[0], "--db-max-open-conns") {
+		t.Fatalf("expected only database errors, got %v", database)
+	}
+	healthcheck := invalid.Filter("app.port", "tls.cert_file")
+	if healthcheck == nil || len(healthcheck.Errors) != 3 {
+		t.Fatalf("expected PORT and tls errors, got %v", healthcheck)
+	}
+	if invalid.Filter("cache", "app.gin_mode") != nil {
+		t.Fatal("expected no errors for unrelated keys")
+	}
+}
+
+func TestConfigReleaseRequiresJWTSecret(t *testing.T) {
+	t.Setenv("GIN_MODE", "release")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "app.jwt_secret: required") {
+		t.Fatalf("expected missing secret to be rejected in release mode, got %v", err)
+	}
+}
+
+func TestConfigSecretFromFile(t *testing.T) {
+	secret := strings.Repeat("s", 40)
+	t.Setenv("JWT_SECRET_FILE", writeFile(t, "jwt_secret", secret+"\n"))
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.App.JWTSecret != secret {
+		t.Errorf("expected secret from file without trailing newline, got %q", cfg.App.JWTSecret)
+	}
+
+	// Đặt cả hai nguồn là lỗi
+	t.Setenv("JWT_SECRET", secret)
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "both JWT_SECRET and JWT_SECRET_FILE") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestConfigPrintRedacted(t *testing.T) {
+	secret := strings.Repeat("x", 40)
+	t.Setenv("JWT_SECRET", secret)
+	t.Setenv("SMTP_PASSWORD", "smtp-secret")
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := cfg.Write(&out, true); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), secret) || strings.Contains(out.String(), "smtp-secret") {
+		t.Fatalf("expected secrets to be redacted:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "jwt_secret: '[REDACTED]'") || !strings.Contains(out.String(), "port: 8080") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	// Output dùng lại được làm file cấu hình
+	file := writeFile(t, "printed.yaml", strings.ReplaceAll(out.String(), "'[REDACTED]'", `""`))
+	reloaded, err := config.Load([]string{"--config", file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.App != cfg.App {
+		t.Errorf("expected printed config to round-trip, got %+v", reloaded.App)
+	}
+}
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/tastygo/config"
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/cors"
+)
+
+// corsRouter tạo router với CORS cho dashboard, các subdomain của partner và /version
+// cho mọi origin
+func corsRouter() *gin.Engine {
+	policy := cors.Policy{
+		AllowedOrigins:   []string{"https://dashboard.tastygo.vn", "https://*.partner.vn"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Request-ID", "Retry-After"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	public := policy
+	public.AllowedOrigins = []string{cors.AnyOrigin}
+	public.AllowCredentials = false
+
+	return api.NewServer(testService, api.WithCORS(cors.Config{
+		Default: policy,
+		Routes:  []cors.Route{{Prefix: "/version", Policy: public}},
+	}))
+}
+
+// corsRequest gửi request kèm Origin và các header bổ sung dạng cặp key, value
+func corsRequest(router http.Handler, method, path, origin string, headers ...string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// hasVary cho biết response có khai báo Vary theo header name
+func hasVary(w *httptest.ResponseRecorder, name string) bool {
+	for _, value := range w.Header().Values("Vary") {
+		if value == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := corsRouter()
+
+	w := corsRequest(router, "OPTIONS", "/api/profile", "https://dashboard.tastygo.vn",
+		"Access-Control-Request-Method", "PUT",
+		"Access-Control-Request-Headers", "authorization, content-type")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":      "https://dashboard.tastygo.vn",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST, PUT, DELETE",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+		"Access-Control-Max-Age":           "600",
+	}
+	for name, value := range expected {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("expected %s %q, got %q", name, value, got)
+		}
+	}
+	for _, name := range []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"} {
+		if !hasVary(w, name) {
+			t.Errorf("expected Vary: %s, got %v", name, w.Header().Values("Vary"))
+		}
+	}
+
+	// Subdomain khớp pattern
+	w = corsRequest(router, "OPTIONS", "/api/auth/login", "https://shop.partner.vn",
+		"Access-Control-Request-Method", "POST")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://shop.partner.vn" {
+		t.Fatalf("expected pattern origin to be allowed, got %d %q", w.Code, w.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestCORSPreflightRejected(t *testing.T) {
+	router := corsRouter()
+
+	cases := []struct {
+		name    string
+		origin  string
+		headers []string
+	}{
+		{"unknown origin", "https://evil.example.com", []string{"Access-Control-Request-Method", "GET"}},
+		{"pattern suffix", "https://partner.vn.evil.com", []string{"Access-Control-Request-Method", "GET"}},
+		{"pattern apex", "https://partner.vn", []string{"Access-Control-Request-Method", "GET"}},
+		{"method", "https://dashboard.tastygo.vn", []string{"Access-Control-Request-Method", "PATCH"}},
+		{"header", "https://dashboard.tastygo.vn", []string{"Access-Control-Request-Method", "GET", "Access-Control-Request-Headers", "X-Debug"}},
+	}
+	for _, tc := range cases {
+		w := corsRequest(router, "OPTIONS", "/api/profile", tc.origin, tc.headers...)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403, got %d", tc.name, w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("%s: expected no Allow-Origin, got %q", tc.name, got)
+		}
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	router := corsRouter()
+
+	// Origin được phép: header CORS có cả trên response lỗi để client đọc được lỗi
+	w := corsRequest(router, "GET", "/api/profile", "https://dashboard.tastygo.vn")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://dashboard.tastygo.vn" {
+		t.Fatalf("expected origin to be echoed, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Request-ID, Retry-After" {
+		t.Fatalf("unexpected Expose-Headers %q", got)
+	}
+	if !hasVary(w, "Origin") {
+		t.Fatal("expected Vary: Origin")
+	}
+
+	// Origin không được phép: request vẫn được xử lý nhưng không có header CORS
+	w = corsRequest(router, "GET", "/api/profile", "https://evil.example.com")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	for _, name := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Credentials", "Access-Control-Expose-Headers"} {
+		if got := w.Header().Get(name); got != "" {
+			t.Errorf("expected no %s, got %q", name, got)
+		}
+	}
+	if !hasVary(w, "Origin") {
+		t.Fatal("expected Vary: Origin on responses without CORS headers")
+	}
+}
+
+func TestCORSRouteOverride(t *testing.T) {
+	router := corsRouter()
+
+	w := corsRequest(router, "GET", "/version", "https://grafana.example.com")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("expected wildcard origin on /version, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Fatalf("expected no credentials with wildcard origin, got %q", got)
+	}
+
+	// Route khác vẫn dùng policy mặc định
+	w = corsRequest(router, "GET", "/api/profile", "https://grafana.example.com")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected default policy outside /version, got %q", got)
+	}
+}
+
+func TestCORSDisabledByDefault(t *testing.T) {
+	router := api.NewServer(testService)
+
+	w := corsRequest(router, "GET", "/api/profile", "https://dashboard.tastygo.vn")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected no CORS headers without WithCORS, got %q", got)
+	}
+}
+
+func TestCORSConfig(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://dashboard.tastygo.vn, https://*.partner.vn")
+	t.Setenv("CORS_ROUTES", "/api/public=*; /api/partner=https://api.partner.vn")
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := cfg.CORS.Policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policy.Default.AllowedOrigins) != 2 || !policy.Default.AllowCredentials {
+		t.Fatalf("unexpected default policy %+v", policy.Default)
+	}
+	if len(policy.Routes) != 2 || policy.Routes[0].Prefix != "/api/public" || policy.Routes[0].Policy.AllowCredentials {
+		t.Fatalf("expected /api/public to allow any origin without credentials, got %+v", policy.Routes)
+	}
+	if !policy.Routes[1].Policy.AllowCredentials {
+		t.Fatal("expected /api/partner to keep credentials")
+	}
+
+	// Mọi origin kèm credentials và origin sai định dạng đều bị từ chối
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*, dashboard.tastygo.vn")
+	_, err = config.Load(nil)
+	verr, ok := err.(*config.ValidationError)
+	if !ok || len(verr.Errors) != 2 {
+		t.Fatalf("expected 2 validation errors, got %v", err)
+	}
+}
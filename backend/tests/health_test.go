@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/database"
+	"github.com/yourusername/tastygo/internal/health"
+	"github.com/yourusername/tastygo/internal/lifecycle"
+)
+
+func TestReadyzReportsEachCheck(t *testing.T) {
+	migrationFS, err := database.MigrationFS(testDB.Dialector.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(testDB, migrationFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lc := lifecycle.NewManager()
+	lc.SetReady(true)
+	lc.Go("worker", func(ctx context.Context) { <-ctx.Done() })
+	defer lc.Shutdown(context.Background())
+
+	checks := health.NewRegistry(time.Second)
+	checks.Register(
+		health.ReadinessChecker(lc),
+		health.DatabaseChecker(testDB),
+		health.MigrationsChecker(migrator),
+		health.WorkersChecker(lc),
+	)
+
+	router := gin.New()
+	api.RegisterProbes(router, checks)
+
+	w := doJSON(router, "GET", "/readyz", nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	response := decode(w)
+	results, _ := response["checks"].([]interface{})
+	if response["status"] != "ok" || len(results) != 4 {
+		t.Fatalf("unexpected readiness report: %v", response)
+	}
+
+	// Một checker thất bại làm /readyz trả về 503 kèm lỗi của checker đó
+	checks.Register(health.CheckerFunc("cache", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}))
+	w = doJSON(router, "GET", "/readyz", nil, "")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", w.Code)
+	}
+	response = decode(w)
+	results, _ = response["checks"].([]interface{})
+	last, _ := results[len(results)-1].(map[string]interface{})
+	if response["status"] != "fail" || last["name"] != "cache" || last["error"] != "connection refused" {
+		t.Fatalf("expected failing cache check in report, got %v", response)
+	}
+
+	// /healthz chỉ phản ánh process còn sống
+	if w := doJSON(router, "GET", "/healthz", nil, ""); w.Code != http.StatusOK {
+		t.Fatalf("expected /healthz 200, got %d", w.Code)
+	}
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	checks := health.NewRegistry(50 * time.Millisecond)
+	checks.Register(health.CheckerFunc("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}))
+
+	report := checks.Run(context.Background())
+	if report.Healthy() || report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("expected slow check to time out, got %+v", report)
+	}
+}
+
+func TestWorkersCheckerDetectsStoppedWorker(t *testing.T) {
+	lc := lifecycle.NewManager()
+	lc.Go("crashed", func(ctx context.Context) {})
+
+	checker := health.WorkersChecker(lc)
+	deadline := time.Now().Add(time.Second)
+	for checker.Check(context.Background()) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected workers check to fail for a stopped worker")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestVersionEndpoint(t *testing.T) {
+	router := gin.New()
+	api.RegisterProbes(router, health.NewRegistry(time.Second))
+
+	w := doJSON(router, "GET", "/version", nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	response := decode(w)
+	if response["go_version"] != runtime.Version() || response["version"] == "" || response["commit"] == "" {
+		t.Fatalf("unexpected version info: %v", response)
+	}
+}
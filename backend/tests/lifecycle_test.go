@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/health"
+	"github.com/yourusername/tastygo/internal/lifecycle"
+)
+
+func TestShutdownHooksRunInOrder(t *testing.T) {
+	lc := lifecycle.NewManager()
+	lc.SetReady(true)
+
+	var order []string
+	stopped := make(chan struct{})
+	lc.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		order = append(order, "worker")
+		close(stopped)
+	})
+	lc.OnShutdown("failing", func(ctx context.Context) error {
+		order = append(order, "failing")
+		return errors.New("boom")
+	})
+	lc.OnShutdown("database", func(ctx context.Context) error {
+		order = append(order, "database")
+		return nil
+	})
+
+	if !lc.Workers()["worker"] {
+		t.Fatal("expected worker to be running")
+	}
+
+	err := lc.Shutdown(context.Background())
+	if err == nil {
+		t.Fatal("expected shutdown to report the failing hook")
+	}
+
+	// Hook lỗi không chặn các hook sau
+	if want := []string{"worker", "failing", "database"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("expected hooks in order %v, got %v", want, order)
+	}
+	<-stopped
+	if lc.Ready() {
+		t.Fatal("expected manager to be not ready after shutdown")
+	}
+	if lc.Workers()["worker"] {
+		t.Fatal("expected worker to be stopped")
+	}
+}
+
+func TestShutdownHookRespectsDeadline(t *testing.T) {
+	lc := lifecycle.NewManager()
+
+	release := make(chan struct{})
+	defer close(release)
+	lc.Go("stuck", func(ctx context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := lc.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestReadinessProbe(t *testing.T) {
+	lc := lifecycle.NewManager()
+	checks := health.NewRegistry(time.Second)
+	checks.Register(health.ReadinessChecker(lc))
+	router := gin.New()
+	api.RegisterProbes(router, checks)
+
+	if w := doJSON(router, "GET", "/readyz", nil, ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 before ready, got %d", w.Code)
+	}
+
+	lc.SetReady(true)
+	if w := doJSON(router, "GET", "/readyz", nil, ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 when ready, got %d", w.Code)
+	}
+
+	// Shutdown báo chưa sẵn sàng trước khi chạy các hook
+	lc.OnShutdown("check", func(ctx context.Context) error {
+		if w := doJSON(router, "GET", "/readyz", nil, ""); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected 503 while draining, got %d", w.Code)
+		}
+		return nil
+	})
+	if err := lc.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/logging"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+func TestSinksHaveIndependentLevels(t *testing.T) {
+	var all, errorsOnly bytes.Buffer
+	logger := logging.NewLogger(logging.DEBUG)
+	logger.SetSinks([]logging.Sink{
+		{Name: "all", Writer: &all, MinLevel: logging.DEBUG},
+		{Name: "errors", Writer: &errorsOnly, MinLevel: logging.ERROR},
+	})
+
+	logger.Debug("debug message")
+	logger.Error("error message")
+
+	if !strings.Contains(all.String(), "debug message") || !strings.Contains(all.String(), "error message") {
+		t.Fatalf("expected DEBUG sink to receive both entries, got %q", all.String())
+	}
+	if strings.Contains(errorsOnly.String(), "debug message") || !strings.Contains(errorsOnly.String(), "error message") {
+		t.Fatalf("expected ERROR sink to receive only the error, got %q", errorsOnly.String())
+	}
+
+	// Level chung chặn trước mọi sink
+	logger.SetLevel(logging.WARN)
+	logger.Info("info message")
+	if strings.Contains(all.String(), "info message") {
+		t.Fatal("expected INFO entry to be dropped at WARN level")
+	}
+}
+
+func TestDebugSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.NewLogger(logging.DEBUG)
+	logger.SetOutput(&buf)
+	logger.SetDebugSampling(2, 3, time.Minute)
+
+	for i := 0; i < 10; i++ {
+		logger.Debug("noisy")
+		logger.Info("important")
+	}
+
+	// Lần 1, 2 được ghi, sau đó cứ 3 lần ghi một: lần 5 và 8
+	if got := strings.Count(buf.String(), `"noisy"`); got != 4 {
+		t.Fatalf("expected 4 sampled DEBUG entries, got %d", got)
+	}
+	if got := strings.Count(buf.String(), `"important"`); got != 10 {
+		t.Fatalf("expected INFO entries not to be sampled, got %d", got)
+	}
+}
+
+func TestRotatingFileRotatesAndCompresses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	file, err := logging.NewRotatingFile(logging.RotateConfig{
+		Path:       path,
+		MaxSize:    64,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := strings.Repeat("x", 40) + "\n"
+	for i := 0; i < 5; i++ {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		// Tên file cũ chứa thời gian tới mili giây
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, _ := filepath.Glob(path + ".*.gz")
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 compressed backups after pruning, got %v", backups)
+	}
+
+	f, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(gz)
+	if string(content) != line {
+		t.Fatalf("unexpected backup content %q", content)
+	}
+
+	current, _ := os.ReadFile(path)
+	if string(current) != line {
+		t.Fatalf("expected current file to hold the last line, got %q", current)
+	}
+}
+
+func TestRotatingFileRecoversFromFailedRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := logging.NewRotatingFile(logging.RotateConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// File bị xóa từ bên ngoài nên rotate không đổi tên được
+	os.Remove(path)
+	if err := file.Rotate(); err == nil {
+		t.Fatal("expected rotate of a missing file to fail")
+	}
+
+	// Lần ghi sau mở lại file thay vì ghi vào handle đã đóng
+	if _, err := file.Write([]byte("after failed rotate\n")); err != nil {
+		t.Fatalf("expected write to recover after failed rotate, got %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "after failed rotate\n" {
+		t.Fatalf("expected log file to be reopened, got %q", content)
+	}
+
+	file.Close()
+	if _, err := file.Write([]byte("x")); err != os.ErrClosed {
+		t.Fatalf("expected write after Close to fail, got %v", err)
+	}
+}
+
+func TestFatalFlushesSinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fatal.log")
+	if logFile := os.Getenv("TEST_FATAL_LOG_FILE"); logFile != "" {
+		file, err := logging.NewRotatingFile(logging.RotateConfig{Path: logFile})
+		if err != nil {
+			os.Exit(2)
+		}
+		logging.DefaultLogger.SetSinks([]logging.Sink{{Name: "file", Writer: file}})
+		logging.Fatal("fatal message")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalFlushesSinks$")
+	cmd.Env = append(os.Environ(), "TEST_FATAL_LOG_FILE="+path)
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("expected exit code 1, got %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "fatal message") {
+		t.Fatalf("expected fatal entry to be written before exit, got %q", content)
+	}
+}
+
+func TestLogLevelEndpoint(t *testing.T) {
+	router := api.NewServer(testService)
+	token := loginSuperAdmin(t, router)["token"].(string)
+	defer logging.DefaultLogger.SetLevel(logging.DefaultLogger.Level())
+
+	w := doJSON(router, "PUT", "/api/admin/log-level", map[string]string{"level": "debug"}, token)
+	if w.Code != http.StatusOK || logging.DefaultLogger.Level() != logging.DEBUG {
+		t.Fatalf("expected level change to DEBUG, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(router, "GET", "/api/admin/log-level", nil, token)
+	if decode(w)["level"] != "DEBUG" {
+		t.Fatalf("expected current level DEBUG, got %s", w.Body.String())
+	}
+
+	if w := doJSON(router, "PUT", "/api/admin/log-level", map[string]string{"level": "verbose"}, token); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown level, got %d", w.Code)
+	}
+
+	// Admin có quyền quản trị khác nhưng không có logs.level_manage
+	createUser(t, "log-level-admin@tastygo.com", models.RoleAdmin, "Password1!")
+	adminToken := login(t, router, "log-level-admin@tastygo.com", "Password1!")["token"].(string)
+	if w := doJSON(router, "PUT", "/api/admin/log-level", map[string]string{"level": "ERROR"}, adminToken); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for admin, got %d", w.Code)
+	}
+
+	// Quyền có thể được giao cho role tùy chỉnh
+	if _, err := testService.CreateRole("operator", "On-call operator", []models.Permission{models.PermLogLevelManage}); err != nil {
+		t.Fatal(err)
+	}
+	createUser(t, "log-level-operator@tastygo.com", "operator", "Password1!")
+	operatorToken := login(t, router, "log-level-operator@tastygo.com", "Password1!")["token"].(string)
+	if w := doJSON(router, "PUT", "/api/admin/log-level", map[string]string{"level": "INFO"}, operatorToken); w.Code != http.StatusOK {
+		t.Fatalf("expected operator role to change level, got %d", w.Code)
+	}
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/logging"
+)
+
+// logBuffer là writer an toàn cho nhiều goroutine, dùng để thu log trong test
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// entries giải mã các dòng log JSON đã ghi
+func (b *logBuffer) entries(t *testing.T) []logging.LogEntry {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var entries []logging.LogEntry
+	scanner := bufio.NewScanner(strings.NewReader(b.buf.String()))
+	for scanner.Scan() {
+		var entry logging.LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %s", scanner.Text())
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// captureLogs chuyển output của DefaultLogger vào buffer cho tới khi test kết thúc
+func captureLogs(t *testing.T) *logBuffer {
+	t.Helper()
+	buf := &logBuffer{}
+	previous := logging.DefaultLogger.Sinks()
+	logging.DefaultLogger.SetOutput(buf)
+	t.Cleanup(func() { logging.DefaultLogger.SetSinks(previous) })
+	return buf
+}
+
+func TestRequestIDPropagatesToServiceLogs(t *testing.T) {
+	router := api.NewServer(testService)
+	logs := captureLogs(t)
+
+	req, _ := http.NewRequest("POST", "/api/auth/login", strings.NewReader(`{"email":"superadmin@tastygo.com","password":"admin123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "req-login-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("login failed: %d", w.Code)
+	}
+	if got := w.Header().Get("X-Request-ID"); got != "req-login-123" {
+		t.Fatalf("expected incoming request ID to be echoed, got %q", got)
+	}
+
+	var serviceLog, accessLog *logging.LogEntry
+	for _, entry := range logs.entries(t) {
+		entry := entry
+		switch entry.Message {
+		case "User logged in successfully":
+			serviceLog = &entry
+		case "HTTP request":
+			accessLog = &entry
+		}
+	}
+
+	if serviceLog == nil || serviceLog.RequestID != "req-login-123" || serviceLog.Route != "/api/auth/login" {
+		t.Fatalf("expected auth.Login log to carry request ID and route, got %+v", serviceLog)
+	}
+	if accessLog == nil || accessLog.RequestID != "req-login-123" || accessLog.Method != "POST" || accessLog.LatencyMS <= 0 {
+		t.Fatalf("expected structured access log with request ID and latency, got %+v", accessLog)
+	}
+}
+
+func TestAccessLogIncludesUserID(t *testing.T) {
+	router := api.NewServer(testService)
+	token := loginSuperAdmin(t, router)["token"].(string)
+	logs := captureLogs(t)
+
+	// Request ID không hợp lệ được thay bằng ID mới
+	req, _ := http.NewRequest("GET", "/api/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Request-ID", "bad id\nwith newline")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	requestID := w.Header().Get("X-Request-ID")
+	if requestID == "" || strings.Contains(requestID, " ") {
+		t.Fatalf("expected a generated request ID, got %q", requestID)
+	}
+
+	entries := logs.entries(t)
+	if len(entries) == 0 {
+		t.Fatal("expected an access log entry")
+	}
+	last := entries[len(entries)-1]
+	if last.Message != "HTTP request" || last.RequestID != requestID || last.UserID == 0 || last.Route != "/api/profile" {
+		t.Fatalf("expected access log with request ID, user ID and route, got %+v", last)
+	}
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/tastygo/internal/auth"
+	"github.com/yourusername/tastygo/internal/cache"
+	"github.com/yourusername/tastygo/internal/database"
+	"github.com/yourusername/tastygo/internal/mailer"
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// testMailer thu thập email được gửi trong quá trình test
+	testMailer = mailer.NewMemoryMailer()
+	// testDB là database dùng chung cho toàn bộ test: SQLite in-memory, hoặc
+	// PostgreSQL khi biến môi trường TEST_POSTGRES_DSN được đặt
+	testDB *gorm.DB
+	// testService là auth.Service được gắn vào testDB và testMailer
+	testService *auth.Service
+)
+
+// TestMain khởi tạo database và auth.Service cho toàn bộ test
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	dbConfig := database.Config{Driver: database.DriverSQLite, DSN: "file:tastygo-test?mode=memory&cache=shared"}
+	if dsn := os.Getenv("TEST_POSTGRES_DSN"); dsn != "" {
+		dbConfig = database.Config{Driver: database.DriverPostgres, DSN: dsn}
+		if err := resetDatabase(dbConfig); err != nil {
+			panic(err)
+		}
+	}
+
+	var err error
+	testDB, err = database.InitDB(dbConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	config := auth.DefaultConfig()
+	config.JWTSecret = []byte("test-secret-key-with-at-least-32-bytes")
+	testService = auth.NewService(repository.NewGormRepositories(testDB), testMailer, cache.NewMemoryCache(1000), config)
+
+	os.Exit(m.Run())
+}
+
+// resetDatabase hoàn tác toàn bộ migration để mỗi lần chạy test bắt đầu từ database trống
+func resetDatabase(config database.Config) error {
+	db, err := database.Open(config)
+	if err != nil {
+		return err
+	}
+	migrationFS, err := database.MigrationFS(config.Driver)
+	if err != nil {
+		return err
+	}
+	migrator, err := database.NewMigrator(db, migrationFS)
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Down(math.MaxInt)
+	return err
+}
+
+// doJSON gửi một request JSON tới router và trả về response recorder
+func doJSON(router http.Handler, method, path string, body interface{}, token string) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decode giải mã body JSON của response
+func decode(w *httptest.ResponseRecorder) map[string]interface{} {
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+// loginSuperAdmin đăng nhập bằng tài khoản superadmin mặc định
+func loginSuperAdmin(t *testing.T, router http.Handler) map[string]interface{} {
+	t.Helper()
+
+	return login(t, router, "superadmin@tastygo.com", "admin123")
+}
+
+// createUser tạo user trực tiếp trong database cho test
+func createUser(t *testing.T, email string, role models.Role, password string) models.User {
+	t.Helper()
+
+	user := models.User{
+		Email:    email,
+		Username: strings.Split(email, "@")[0],
+		Role:     role,
+		Active:   true,
+	}
+	if err := user.SetPassword(password); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// login đăng nhập và trả về response đã giải mã
+func login(t *testing.T, router http.Handler, email, password string) map[string]interface{} {
+	t.Helper()
+
+	w := doJSON(router, "POST", "/api/auth/login", map[string]string{
+		"email":    email,
+		"password": password,
+	}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login failed: %d %s", w.Code, w.Body.String())
+	}
+	return decode(w)
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/yourusername/tastygo/config"
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/metrics"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+func TestLoginOutcomeMetrics(t *testing.T) {
+	router := api.NewServer(testService)
+	createUser(t, "metrics-user@tastygo.com", models.RoleAdmin, "Password1!")
+
+	outcome := func(name string) float64 {
+		return testutil.ToFloat64(metrics.LoginAttempts.WithLabelValues(name))
+	}
+	success, badPassword, notFound := outcome(metrics.LoginSuccess), outcome(metrics.LoginBadPassword), outcome(metrics.LoginNotFound)
+
+	login(t, router, "metrics-user@tastygo.com", "Password1!")
+	doJSON(router, "POST", "/api/auth/login", map[string]string{"email": "metrics-user@tastygo.com", "password": "wrong"}, "")
+	doJSON(router, "POST", "/api/auth/login", map[string]string{"email": "nobody@tastygo.com", "password": "wrong"}, "")
+
+	if outcome(metrics.LoginSuccess) != success+1 || outcome(metrics.LoginBadPassword) != badPassword+1 || outcome(metrics.LoginNotFound) != notFound+1 {
+		t.Fatal("expected one success, one bad_password and one not_found login to be counted")
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	router := api.NewServer(testService)
+	token := loginSuperAdmin(t, router)["token"].(string)
+	doJSON(router, "GET", "/api/profile", nil, token)
+
+	before := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues("GET", "/api/profile", "401"))
+	doJSON(router, "GET", "/api/profile", nil, "")
+	if after := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues("GET", "/api/profile", "401")); after != before+1 {
+		t.Fatalf("expected request counter for route template to increase, got %v -> %v", before, after)
+	}
+
+	// /metrics chỉ có trên listener nội bộ, không có trên router công khai
+	if w := doJSON(router, "GET", "/metrics", nil, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected /metrics to be absent from the public router, got %d", w.Code)
+	}
+	w := doJSON(api.NewMetricsServer(testService), "GET", "/metrics", nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`tastygo_http_request_duration_seconds_bucket{method="POST",route="/api/auth/login"`,
+		`tastygo_db_query_duration_seconds_count{operation="query",table="users"}`,
+		`tastygo_cache_requests_total{result=`,
+		"tastygo_auth_active_sessions ",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected /metrics to contain %q", want)
+		}
+	}
+	if strings.Contains(body, "tastygo_auth_active_sessions 0\n") {
+		t.Error("expected active sessions gauge to count the superadmin session")
+	}
+}
+
+func TestMetricsAddrValidation(t *testing.T) {
+	if cfg := config.Default(); cfg.Metrics.Addr != "127.0.0.1:9090" {
+		t.Fatalf("expected metrics to listen on loopback by default, got %q", cfg.Metrics.Addr)
+	}
+
+	cases := []struct {
+		addr string
+		want string
+	}{
+		{":8080", "metrics.addr: port must differ from app.port"},
+		{"0.0.0.0", "metrics.addr: address 0.0.0.0: missing port in address"},
+		{":metrics", "metrics.addr: port must be between 1 and 65535"},
+	}
+	for _, tc := range cases {
+		t.Setenv("METRICS_ADDR", tc.addr)
+		_, err := config.Load(nil)
+		verr, ok := err.(*config.ValidationError)
+		if !ok || len(verr.Errors) != 1 || !strings.HasPrefix(verr.Errors[0], tc.want) {
+			t.Errorf("METRICS_ADDR=%q: expected %q, got %v", tc.addr, tc.want, err)
+		}
+	}
+
+	t.Setenv("METRICS_ADDR", "")
+	if _, err := config.Load(nil); err != nil {
+		t.Fatalf("expected empty METRICS_ADDR to disable metrics, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+// totp tính mã TOTP theo RFC 6238 để giả lập ứng dụng authenticator
+func totp(secret string, at time.Time) string {
+	key, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	router := api.NewServer(testService)
+	createUser(t, "mfa-admin@tastygo.com", models.RoleAdmin, "Secret#123")
+
+	session := login(t, router, "mfa-admin@tastygo.com", "Secret#123")
+	token := session["token"].(string)
+
+	// Đăng ký 2FA
+	w := doJSON(router, "POST", "/api/auth/mfa/enroll", nil, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	secret := decode(w)["secret"].(string)
+
+	w = doJSON(router, "POST", "/api/auth/mfa/confirm", map[string]string{"code": totp(secret, time.Now())}, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	recoveryCodes := decode(w)["recovery_codes"].([]interface{})
+	if len(recoveryCodes) == 0 {
+		t.Fatal("Expected recovery codes")
+	}
+
+	// Đăng nhập lần sau phải trả về challenge thay vì token
+	challenge := login(t, router, "mfa-admin@tastygo.com", "Secret#123")
+	if challenge["mfa_required"] != true || challenge["token"] != nil {
+		t.Fatalf("Expected mfa challenge, got %v", challenge)
+	}
+	challengeToken := challenge["challenge_token"].(string)
+
+	// Challenge token không được dùng như access token
+	if w := doJSON(router, "GET", "/api/profile", nil, challengeToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected challenge token to be rejected, got %d", w.Code)
+	}
+
+	// Mã TOTP đã dùng để xác nhận không được dùng lại
+	w = doJSON(router, "POST", "/api/auth/mfa/verify", map[string]string{
+		"challenge_token": challengeToken,
+		"code":            totp(secret, time.Now()),
+	}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected replayed code to be rejected, got %d", w.Code)
+	}
+
+	// Mã khôi phục chỉ dùng được một lần
+	recovery := map[string]string{
+		"challenge_token": challengeToken,
+		"recovery_code":   recoveryCodes[0].(string),
+	}
+	if w := doJSON(router, "POST", "/api/auth/mfa/verify", recovery, ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected recovery code login to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(router, "POST", "/api/auth/mfa/verify", recovery, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected used recovery code to be rejected, got %d", w.Code)
+	}
+}
+
+func TestMFAPolicyEnforcement(t *testing.T) {
+	router := api.NewServer(testService)
+	user := createUser(t, "mfa-customer@tastygo.com", models.RoleCustomer, "Secret#123")
+	admin := loginSuperAdmin(t, router)["token"].(string)
+
+	w := doJSON(router, "PUT", "/api/admin/mfa-policies", map[string]interface{}{"role": "customer", "required": true}, admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	defer doJSON(router, "PUT", "/api/admin/mfa-policies", map[string]interface{}{"role": "customer", "required": false}, admin)
+
+	token := login(t, router, "mfa-customer@tastygo.com", "Secret#123")["token"].(string)
+	if w := doJSON(router, "GET", "/api/profile", nil, token); w.Code != http.StatusForbidden {
+		t.Errorf("Expected enrollment to be enforced, got %d", w.Code)
+	}
+	if w := doJSON(router, "POST", "/api/auth/mfa/enroll", nil, token); w.Code != http.StatusOK {
+		t.Errorf("Expected enrollment route to stay reachable, got %d", w.Code)
+	}
+
+	// Superadmin reset 2FA của user, các phiên hiện có của user bị thu hồi
+	w = doJSON(router, "POST", "/api/admin/users/reset-mfa", map[string]interface{}{"user_id": user.ID}, admin)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := doJSON(router, "POST", "/api/auth/mfa/enroll", nil, token); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected sessions to be revoked after reset, got %d", w.Code)
+	}
+}
+
+func TestResetMFAGuards(t *testing.T) {
+	router := api.NewServer(testService)
+	if _, err := testService.CreateRole("mfa_support", "MFA support", []models.Permission{models.PermUsersResetMFA}); err != nil {
+		t.Fatal(err)
+	}
+	support := createUser(t, "mfa-support@tastygo.com", "mfa_support", "Secret#123")
+	token := login(t, router, "mfa-support@tastygo.com", "Secret#123")["token"].(string)
+
+	var superAdmin models.User
+	testDB.Where("role = ?", models.RoleSuperAdmin).First(&superAdmin)
+	for name, target := range map[string]uint{"superadmin": superAdmin.ID, "self": support.ID} {
+		w := doJSON(router, "POST", "/api/admin/users/reset-mfa", map[string]interface{}{"user_id": target}, token)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected reset to be rejected, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestDisableMFACodeCannotBeReplayed(t *testing.T) {
+	router := api.NewServer(testService)
+	if _, err := testService.CreateRole("mfa_replay", "MFA replay", []models.Permission{}); err != nil {
+		t.Fatal(err)
+	}
+	createUser(t, "mfa-replay@tastygo.com", "mfa_replay", "Secret#123")
+	token := login(t, router, "mfa-replay@tastygo.com", "Secret#123")["token"].(string)
+
+	secret := decode(doJSON(router, "POST", "/api/auth/mfa/enroll", nil, token))["secret"].(string)
+	w := doJSON(router, "POST", "/api/auth/mfa/confirm", map[string]string{"code": totp(secret, time.Now().Add(-30*time.Second))}, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Tắt 2FA bị chính sách chặn nhưng mã đã được kiểm tra vẫn bị đánh dấu là đã dùng
+	if err := testService.SetMFAPolicy("mfa_replay", true); err != nil {
+		t.Fatal(err)
+	}
+	code := totp(secret, time.Now())
+	w = doJSON(router, "POST", "/api/auth/mfa/disable", map[string]string{"password": "Secret#123", "code": code}, token)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected policy to block disabling 2FA, got %d: %s", w.Code, w.Body.String())
+	}
+
+	challenge := login(t, router, "mfa-replay@tastygo.com", "Secret#123")["challenge_token"].(string)
+	w = doJSON(router, "POST", "/api/auth/mfa/verify", map[string]string{"challenge_token": challenge, "code": code}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected code used for disable to be rejected, got %d", w.Code)
+	}
+}
+
+func TestDisableMFALockout(t *testing.T) {
+	router := api.NewServer(testService)
+	createUser(t, "mfa-lockout@tastygo.com", models.RoleCustomer, "Secret#123")
+	token := login(t, router, "mfa-lockout@tastygo.com", "Secret#123")["token"].(string)
+
+	secret := decode(doJSON(router, "POST", "/api/auth/mfa/enroll", nil, token))["secret"].(string)
+	w := doJSON(router, "POST", "/api/auth/mfa/confirm", map[string]string{"code": totp(secret, time.Now().Add(-30*time.Second))}, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Mật khẩu sai và mã sai đều được tính vào số lần thử sai
+	for i := 0; i < 5; i++ {
+		body := map[string]string{"password": "Wrong#123", "code": totp(secret, time.Now())}
+		if i%2 == 1 {
+			body = map[string]string{"password": "Secret#123", "code": "000000"}
+		}
+		if w := doJSON(router, "POST", "/api/auth/mfa/disable", body, token); w.Code != http.StatusBadRequest {
+			t.Fatalf("attempt %d: expected 400, got %d: %s", i+1, w.Code, w.Body.String())
+		}
+	}
+
+	// Tài khoản bị khóa: thông tin đúng cũng bị từ chối
+	w = doJSON(router, "POST", "/api/auth/mfa/disable", map[string]string{"password": "Secret#123", "code": totp(secret, time.Now())}, token)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected locked account to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+	var user models.User
+	testDB.Where("email = ?", "mfa-lockout@tastygo.com").First(&user)
+	if !user.TOTPEnabled {
+		t.Error("Expected 2FA to stay enabled while the account is locked")
+	}
+}
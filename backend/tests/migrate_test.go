@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/auth"
+	"github.com/yourusername/tastygo/internal/cache"
+	"github.com/yourusername/tastygo/internal/database"
+	"github.com/yourusername/tastygo/internal/mailer"
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func TestMigrator(t *testing.T) {
+	db, err := database.Open(database.Config{
+		Driver: database.DriverSQLite,
+		DSN:    "file:tastygo-migrate-test?mode=memory&cache=shared",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := fstest.MapFS{
+		"001_create_widgets.up.sql":     {Data: []byte("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT);")},
+		"001_create_widgets.down.sql":   {Data: []byte("DROP TABLE widgets;")},
+		"002_add_widget_color.up.sql":   {Data: []byte("ALTER TABLE widgets ADD COLUMN color TEXT;")},
+		"002_add_widget_color.down.sql": {Data: []byte("ALTER TABLE widgets DROP COLUMN color;")},
+	}
+
+	migrator, err := database.NewMigrator(db, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("expected 2 pending migrations, got %d (%v)", len(pending), err)
+	}
+
+	// Up áp dụng theo thứ tự phiên bản
+	applied, err := migrator.Up()
+	if err != nil || len(applied) != 2 || applied[0].Version != 1 {
+		t.Fatalf("unexpected up result: %+v (%v)", applied, err)
+	}
+	if err := db.Exec("INSERT INTO widgets (name, color) VALUES ('a', 'red')").Error; err != nil {
+		t.Fatalf("schema not applied: %v", err)
+	}
+
+	// Chạy lại up không còn gì để áp dụng
+	applied, err = migrator.Up()
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("expected no pending migrations, got %d (%v)", len(applied), err)
+	}
+
+	// Down chỉ hoàn tác migration gần nhất
+	reverted, err := migrator.Down(1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("unexpected down result: %+v (%v)", reverted, err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil || len(statuses) != 2 {
+		t.Fatalf("unexpected status: %+v (%v)", statuses, err)
+	}
+	if statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil {
+		t.Fatalf("expected 001 applied and 002 pending, got %+v", statuses)
+	}
+
+	// Sửa migration đã áp dụng phải bị phát hiện qua checksum
+	files["001_create_widgets.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE widgets (id INTEGER PRIMARY KEY);")}
+	modified, err := database.NewMigrator(db, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := modified.Up(); !errors.Is(err, database.ErrChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+
+	// Tên file sai định dạng bị từ chối
+	files["widgets.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	if _, err := database.NewMigrator(db, files); err == nil {
+		t.Fatal("expected error for invalid migration file name")
+	}
+}
+
+func TestEmbeddedMigrationsApplied(t *testing.T) {
+	migrationFS, err := database.MigrationFS(testDB.Dialector.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := database.NewMigrator(testDB, migrationFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil || status.Modified || status.Missing {
+			t.Fatalf("migration %03d_%s not cleanly applied: %+v", status.Version, status.Name, status)
+		}
+	}
+}
+
+// Các model dưới đây là schema của phiên bản trước khi có migration, được tạo bằng AutoMigrate
+type legacyUser struct {
+	ID               uint   `gorm:"primarykey"`
+	Email            string `gorm:"uniqueIndex;not null"`
+	Username         string `gorm:"uniqueIndex;not null"`
+	PasswordHash     string `gorm:"not null"`
+	Role             string `gorm:"not null"`
+	Active           bool   `gorm:"default:true"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	LastLogin        *time.Time
+	FailedLoginCount int `gorm:"default:0"`
+	LastFailedLogin  *time.Time
+	LockedUntil      *time.Time
+	DeletedAt        gorm.DeletedAt    `gorm:"index"`
+	Profile          legacyUserProfile `gorm:"foreignKey:UserID"`
+}
+
+type legacyUserProfile struct {
+	ID        uint `gorm:"primarykey"`
+	UserID    uint `gorm:"uniqueIndex;not null"`
+	FullName  string
+	Phone     string
+	Address   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type legacySession struct {
+	ID        uint      `gorm:"primarykey"`
+	UserID    uint      `gorm:"index;not null"`
+	Token     string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+	IPAddress string
+	UserAgent string
+}
+
+type legacyActivityLog struct {
+	ID           uint   `gorm:"primarykey"`
+	UserID       uint   `gorm:"index;not null"`
+	ActivityType string `gorm:"not null"`
+	Description  string
+	IPAddress    string
+	UserAgent    string
+	CreatedAt    time.Time
+}
+
+func (legacyUser) TableName() string        { return "users" }
+func (legacyUserProfile) TableName() string { return "user_profiles" }
+func (legacySession) TableName() string     { return "sessions" }
+func (legacyActivityLog) TableName() string { return "activity_logs" }
+
+func TestMigrateUpgradesAutoMigrateDatabase(t *testing.T) {
+	config := database.Config{Driver: database.DriverSQLite, DSN: "file:tastygo-upgrade-test?mode=memory&cache=shared"}
+	legacy, err := database.Open(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.AutoMigrate(&legacyUser{}, &legacyUserProfile{}, &legacySession{}, &legacyActivityLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Legacy#123"), bcrypt.MinCost)
+	user := legacyUser{
+		Email:        "legacy@tastygo.com",
+		Username:     "legacy",
+		PasswordHash: string(hash),
+		Role:         string(models.RoleAdmin),
+		Active:       true,
+		Profile:      legacyUserProfile{FullName: "Legacy Admin"},
+	}
+	if err := legacy.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	legacy.Create(&legacySession{UserID: user.ID, Token: "legacy-token", ExpiresAt: time.Now().Add(time.Hour)})
+	legacy.Exec("INSERT INTO activity_logs (user_id, activity_type, created_at) VALUES (?, 'login', ?)", user.ID, time.Now())
+
+	// Server khởi động trên database cũ: mọi migration được áp dụng, dữ liệu giữ nguyên
+	db, err := database.InitDB(config)
+	if err != nil {
+		t.Fatalf("expected existing database to be upgraded, got %v", err)
+	}
+	migrationFS, _ := database.MigrationFS(config.Driver)
+	migrator, err := database.NewMigrator(db, migrationFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := migrator.Pending(); err != nil || len(pending) != 0 {
+		t.Fatalf("expected no pending migrations, got %d (%v)", len(pending), err)
+	}
+
+	authConfig := auth.DefaultConfig()
+	authConfig.JWTSecret = []byte("test-secret-key-with-at-least-32-bytes")
+	service := auth.NewService(repository.NewGormRepositories(db), mailer.NewMemoryMailer(), cache.NewMemoryCache(100), authConfig)
+	if _, err := service.Login(context.Background(), "legacy@tastygo.com", "Legacy#123", "127.0.0.1", "test"); err != nil {
+		t.Fatalf("expected existing user to log in after upgrade, got %v", err)
+	}
+	var logs []models.ActivityLog
+	if err := db.Where("user_id = ?", user.ID).Find(&logs).Error; err != nil || len(logs) < 2 {
+		t.Fatalf("expected existing activity logs to be readable, got %d (%v)", len(logs), err)
+	}
+
+	// Các migration sau schema ban đầu hoàn tác được về đúng schema cũ và áp dụng lại
+	statuses, _ := migrator.Status()
+	if _, err := migrator.Down(len(statuses) - 1); err != nil {
+		t.Fatalf("expected down to the initial schema, got %v", err)
+	}
+	if db.Migrator().HasColumn(&models.User{}, "totp_secret") || db.Migrator().HasTable(&models.APIKey{}) {
+		t.Fatal("expected later migrations to be reverted")
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("expected migrations to apply again, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/mailer"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+// waitForMail chờ email được gửi bất đồng bộ tới địa chỉ to
+func waitForMail(t *testing.T, to string) mailer.Message {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if msg, ok := testMailer.Last(to); ok {
+			return msg
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected an email to %s", to)
+	return mailer.Message{}
+}
+
+func TestForgotPasswordFlow(t *testing.T) {
+	router := api.NewServer(testService)
+	createUser(t, "forgot@tastygo.com", models.RoleCustomer, "Secret#123")
+	oldSession := login(t, router, "forgot@tastygo.com", "Secret#123")["token"].(string)
+
+	// Email không tồn tại và email tồn tại phải trả về cùng một phản hồi
+	unknown := doJSON(router, "POST", "/api/auth/forgot-password", map[string]string{"email": "nobody@tastygo.com"}, "")
+	known := doJSON(router, "POST", "/api/auth/forgot-password", map[string]string{"email": "forgot@tastygo.com"}, "")
+	if unknown.Code != http.StatusOK || known.Code != http.StatusOK || unknown.Body.String() != known.Body.String() {
+		t.Fatalf("Expected identical responses, got %d %q and %d %q", unknown.Code, unknown.Body, known.Code, known.Body)
+	}
+
+	msg := waitForMail(t, "forgot@tastygo.com")
+	match := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("Expected reset link in email body: %s", msg.Body)
+	}
+	token := match[1]
+
+	// Mật khẩu yếu bị từ chối và không làm mất token
+	w := doJSON(router, "POST", "/api/auth/reset-password", map[string]string{"token": token, "password": "weak"}, "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected weak password to be rejected, got %d", w.Code)
+	}
+
+	w = doJSON(router, "POST", "/api/auth/reset-password", map[string]string{"token": token, "password": "NewSecret#456"}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Token chỉ dùng được một lần
+	w = doJSON(router, "POST", "/api/auth/reset-password", map[string]string{"token": token, "password": "Another#789"}, "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected reused token to be rejected, got %d", w.Code)
+	}
+
+	// Các phiên cũ bị thu hồi, mật khẩu mới có hiệu lực
+	if w := doJSON(router, "GET", "/api/profile", nil, oldSession); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected old session to be revoked, got %d", w.Code)
+	}
+	login(t, router, "forgot@tastygo.com", "NewSecret#456")
+}
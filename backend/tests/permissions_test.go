@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+func TestCustomRolePermissions(t *testing.T) {
+	router := api.NewServer(testService)
+	admin := loginSuperAdmin(t, router)["token"].(string)
+
+	// Tạo role "support" chỉ được đọc logs
+	w := doJSON(router, "POST", "/api/admin/roles", map[string]interface{}{
+		"name":        "support",
+		"description": "Read-only support staff",
+		"permissions": []string{"logs.read"},
+	}, admin)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	createUser(t, "support@tastygo.com", models.Role("support"), "Secret#123")
+	token := login(t, router, "support@tastygo.com", "Secret#123")["token"].(string)
+
+	if w := doJSON(router, "GET", "/api/admin/logs", nil, token); w.Code != http.StatusOK {
+		t.Errorf("Expected support role to read logs, got %d", w.Code)
+	}
+	if w := doJSON(router, "GET", "/api/admin/users/admins", nil, token); w.Code != http.StatusForbidden {
+		t.Errorf("Expected support role to be denied users.read, got %d", w.Code)
+	}
+
+	// Quyền không hợp lệ bị từ chối
+	w = doJSON(router, "PUT", "/api/admin/roles/support", map[string]interface{}{
+		"permissions": []string{"logs.read", "everything"},
+	}, admin)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected invalid permission to be rejected, got %d", w.Code)
+	}
+
+	// Cập nhật role có hiệu lực ngay (cache được xóa)
+	w = doJSON(router, "PUT", "/api/admin/roles/support", map[string]interface{}{
+		"permissions": []string{"logs.read", "users.read"},
+	}, admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := doJSON(router, "GET", "/api/admin/users/admins", nil, token); w.Code != http.StatusOK {
+		t.Errorf("Expected updated permissions to apply, got %d", w.Code)
+	}
+
+	// Không thể xóa role đang được gán hoặc role hệ thống
+	if w := doJSON(router, "DELETE", "/api/admin/roles/support", nil, admin); w.Code != http.StatusConflict {
+		t.Errorf("Expected in-use role deletion to conflict, got %d", w.Code)
+	}
+	if w := doJSON(router, "DELETE", "/api/admin/roles/admin", nil, admin); w.Code != http.StatusForbidden {
+		t.Errorf("Expected system role deletion to be forbidden, got %d", w.Code)
+	}
+}
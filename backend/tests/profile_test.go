@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+func TestProfileSelfService(t *testing.T) {
+	router := api.NewServer(testService)
+	createUser(t, "profile@tastygo.com", models.RoleCustomer, "Secret#123")
+	other := login(t, router, "profile@tastygo.com", "Secret#123")["token"].(string)
+	token := login(t, router, "profile@tastygo.com", "Secret#123")["token"].(string)
+
+	// Đọc profile để đưa vào cache
+	if w := doJSON(router, "GET", "/api/profile", nil, token); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	w := doJSON(router, "PATCH", "/api/profile", map[string]string{
+		"full_name": "Profile User",
+		"phone":     "0911111111",
+		"address":   "1 Main Street",
+	}, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Cache phải được xóa sau khi cập nhật
+	profile := decode(doJSON(router, "GET", "/api/profile", nil, token))["profile"].(map[string]interface{})
+	if profile["phone"] != "0911111111" || profile["address"] != "1 Main Street" {
+		t.Errorf("Expected updated profile, got %v", profile)
+	}
+
+	// Đổi mật khẩu cần mật khẩu hiện tại đúng và mật khẩu mới đủ mạnh
+	w = doJSON(router, "POST", "/api/profile/password", map[string]string{"current_password": "wrong", "new_password": "NewSecret#456"}, token)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected wrong current password to be rejected, got %d", w.Code)
+	}
+	w = doJSON(router, "POST", "/api/profile/password", map[string]string{"current_password": "Secret#123", "new_password": "weak"}, token)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected weak password to be rejected, got %d", w.Code)
+	}
+	w = doJSON(router, "POST", "/api/profile/password", map[string]string{"current_password": "Secret#123", "new_password": "NewSecret#456"}, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Phiên hiện tại vẫn dùng được, các phiên khác bị thu hồi
+	if w := doJSON(router, "GET", "/api/profile", nil, token); w.Code != http.StatusOK {
+		t.Errorf("Expected current session to remain valid, got %d", w.Code)
+	}
+	if w := doJSON(router, "GET", "/api/profile", nil, other); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected other session to be revoked, got %d", w.Code)
+	}
+	login(t, router, "profile@tastygo.com", "NewSecret#456")
+}
+
+func TestProfileCacheInvalidatedByAdminUpdate(t *testing.T) {
+	router := api.NewServer(testService)
+	admin := loginSuperAdmin(t, router)["token"].(string)
+	user := createUser(t, "profile-cache@tastygo.com", models.RoleCustomer, "Secret#123")
+	token := login(t, router, "profile-cache@tastygo.com", "Secret#123")["token"].(string)
+
+	// Đọc profile để đưa vào cache
+	if w := doJSON(router, "GET", "/api/profile", nil, token); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// Admin sửa user: tag user:<id> bị vô hiệu hóa nên profile được đọc lại từ database
+	w := doJSON(router, "PATCH", fmt.Sprintf("/api/admin/users/%d", user.ID), map[string]interface{}{
+		"username": "profile_cache_renamed",
+		"profile":  map[string]string{"full_name": "Renamed User"},
+	}, admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	response := decode(doJSON(router, "GET", "/api/profile", nil, token))
+	if response["username"] != "profile_cache_renamed" || response["profile"].(map[string]interface{})["full_name"] != "Renamed User" {
+		t.Errorf("Expected profile to reflect admin update, got %v", response)
+	}
+}
+
+func TestChangePasswordLockout(t *testing.T) {
+	router := api.NewServer(testService)
+	createUser(t, "profile-lockout@tastygo.com", models.RoleCustomer, "Secret#123")
+	token := login(t, router, "profile-lockout@tastygo.com", "Secret#123")["token"].(string)
+
+	// Nhập sai mật khẩu hiện tại được tính như đăng nhập sai và khóa tài khoản sau 5 lần
+	wrong := map[string]string{"current_password": "wrong", "new_password": "NewSecret#456"}
+	for i := 0; i < 5; i++ {
+		if w := doJSON(router, "POST", "/api/profile/password", wrong, token); w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected wrong current password to be rejected, got %d", w.Code)
+		}
+	}
+
+	// Khi đang bị khóa, kể cả mật khẩu đúng cũng bị từ chối
+	correct := map[string]string{"current_password": "Secret#123", "new_password": "NewSecret#456"}
+	if w := doJSON(router, "POST", "/api/profile/password", correct, token); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected locked account to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+	w := doJSON(router, "POST", "/api/auth/login", map[string]string{"email": "profile-lockout@tastygo.com", "password": "Secret#123"}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected login to be locked too, got %d", w.Code)
+	}
+}
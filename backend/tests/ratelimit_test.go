@@ -0,0 +1,265 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/auth"
+	"github.com/yourusername/tastygo/internal/models"
+	"github.com/yourusername/tastygo/internal/ratelimit"
+)
+
+// testPolicies trả về policy cho mọi nhóm route, có thể ghi đè từng nhóm
+func testPolicies(overrides ...ratelimit.Policy) []ratelimit.Policy {
+	policies := map[string]ratelimit.Policy{
+		ratelimit.PolicyLogin: {Name: ratelimit.PolicyLogin, Limit: 100, Window: time.Minute, Key: ratelimit.KeyIP},
+		ratelimit.PolicyAuth:  {Name: ratelimit.PolicyAuth, Limit: 100, Window: time.Minute, Key: ratelimit.KeyIP},
+		ratelimit.PolicyRead:  {Name: ratelimit.PolicyRead, Limit: 100, Window: time.Minute, Key: ratelimit.KeyUser},
+		ratelimit.PolicyWrite: {Name: ratelimit.PolicyWrite, Limit: 100, Window: time.Minute, Key: ratelimit.KeyUser},
+		ratelimit.PolicyAPI:   {Name: ratelimit.PolicyAPI, Limit: 100, Window: time.Minute, Key: ratelimit.KeyIP},
+	}
+	for _, policy := range overrides {
+		policies[policy.Name] = policy
+	}
+
+	var result []ratelimit.Policy
+	for _, policy := range policies {
+		result = append(result, policy)
+	}
+	return result
+}
+
+// loginFrom gửi request đăng nhập sai mật khẩu từ một IP
+func loginFrom(router http.Handler, ip string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/api/auth/login", strings.NewReader(`{"email":"nobody@tastygo.com","password":"wrong"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":12345"
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ratelimit.ParsePolicy("login", "10/1m:user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Limit != 10 || policy.Window != time.Minute || policy.Key != ratelimit.KeyUser {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	if policy, err := ratelimit.ParsePolicy("read", "300/1h"); err != nil || policy.Key != ratelimit.KeyIP {
+		t.Errorf("expected ip key by default, got %+v (%v)", policy, err)
+	}
+	if policy, err := ratelimit.ParsePolicy("read", "off"); err != nil || policy.Enabled() {
+		t.Errorf("expected disabled policy, got %+v (%v)", policy, err)
+	}
+
+	for _, spec := range []string{"10", "x/1m", "10/never", "10/1m:session"} {
+		if _, err := ratelimit.ParsePolicy("login", spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestLoginRateLimitPerIP(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), testPolicies(
+		ratelimit.Policy{Name: ratelimit.PolicyLogin, Limit: 2, Window: time.Minute, Key: ratelimit.KeyIP},
+	)...)
+	router := api.NewServer(testService, api.WithRateLimiter(limiter))
+
+	w := loginFrom(router, "203.0.113.1")
+	if w.Code == http.StatusTooManyRequests {
+		t.Fatalf("expected first attempt to reach the handler, got %d", w.Code)
+	}
+	if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "1" {
+		t.Errorf("unexpected rate limit headers: %v", w.Header())
+	}
+	if w.Header().Get("RateLimit-Policy") != "2;w=60" {
+		t.Errorf("unexpected RateLimit-Policy: %q", w.Header().Get("RateLimit-Policy"))
+	}
+
+	loginFrom(router, "203.0.113.1")
+	w = loginFrom(router, "203.0.113.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected third attempt to be rejected, got %d", w.Code)
+	}
+	if w.Header().Get("RateLimit-Remaining") != "0" || w.Header().Get("Retry-After") != "30" {
+		t.Errorf("unexpected headers on rejection: %v", w.Header())
+	}
+
+	// IP khác có giới hạn riêng
+	if w := loginFrom(router, "203.0.113.2"); w.Code == http.StatusTooManyRequests {
+		t.Errorf("expected other IP to be allowed, got %d", w.Code)
+	}
+}
+
+func TestRateLimitIgnoresForwardedForFromUntrustedClient(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), testPolicies(
+		ratelimit.Policy{Name: ratelimit.PolicyLogin, Limit: 2, Window: time.Minute, Key: ratelimit.KeyIP},
+	)...)
+	loginVia := func(router http.Handler, remoteIP, forwardedFor string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/auth/login", strings.NewReader(`{"email":"nobody@tastygo.com","password":"wrong"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.RemoteAddr = remoteIP + ":12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Không cấu hình proxy: đổi X-Forwarded-For mỗi request vẫn rơi vào cùng bucket theo địa chỉ kết nối
+	router := api.NewServer(testService, api.WithRateLimiter(limiter))
+	loginVia(router, "203.0.113.50", "198.51.100.1")
+	loginVia(router, "203.0.113.50", "198.51.100.2")
+	if w := loginVia(router, "203.0.113.50", "198.51.100.3"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected spoofed X-Forwarded-For to share the bucket, got %d", w.Code)
+	}
+
+	// Sau proxy tin cậy, mỗi client thật có bucket riêng
+	router = api.NewServer(testService, api.WithRateLimiter(limiter), api.WithTrustedProxies("10.0.0.0/8"))
+	loginVia(router, "10.0.0.5", "198.51.100.10")
+	loginVia(router, "10.0.0.5", "198.51.100.10")
+	if w := loginVia(router, "10.0.0.5", "198.51.100.11"); w.Code == http.StatusTooManyRequests {
+		t.Errorf("expected other client behind trusted proxy to be allowed, got %d", w.Code)
+	}
+	if w := loginVia(router, "10.0.0.5", "198.51.100.10"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected forwarded client to be limited, got %d", w.Code)
+	}
+}
+
+func TestReadRateLimitPerUser(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), testPolicies(
+		ratelimit.Policy{Name: ratelimit.PolicyRead, Limit: 2, Window: time.Minute, Key: ratelimit.KeyUser},
+	)...)
+	router := api.NewServer(testService, api.WithRateLimiter(limiter))
+	createUser(t, "ratelimit-a@tastygo.com", models.RoleCustomer, "Secret#123")
+	createUser(t, "ratelimit-b@tastygo.com", models.RoleCustomer, "Secret#123")
+	first := login(t, router, "ratelimit-a@tastygo.com", "Secret#123")["token"].(string)
+	second := login(t, router, "ratelimit-b@tastygo.com", "Secret#123")["token"].(string)
+
+	for i := 0; i < 2; i++ {
+		if w := doJSON(router, "GET", "/api/profile", nil, first); w.Code != http.StatusOK {
+			t.Fatalf("expected request %d to succeed, got %d", i+1, w.Code)
+		}
+	}
+	if w := doJSON(router, "GET", "/api/profile", nil, first); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected read limit to be exceeded, got %d", w.Code)
+	}
+
+	// Cùng IP nhưng user khác không bị ảnh hưởng, request ghi dùng policy riêng
+	if w := doJSON(router, "GET", "/api/profile", nil, second); w.Code != http.StatusOK {
+		t.Errorf("expected other user to be allowed, got %d", w.Code)
+	}
+	if w := doJSON(router, "PATCH", "/api/profile", map[string]string{"phone": "0922222222"}, first); w.Code != http.StatusOK {
+		t.Errorf("expected write to use its own limit, got %d", w.Code)
+	}
+}
+
+func TestReadRateLimitPerAPIKey(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), testPolicies(
+		ratelimit.Policy{Name: ratelimit.PolicyRead, Limit: 2, Window: time.Minute, Key: ratelimit.KeyAPIKey},
+	)...)
+	router := api.NewServer(testService, api.WithRateLimiter(limiter))
+	owner := createPartner(t, "ratelimit-key@tastygo.com", "partner_ratelimit")
+	input := auth.CreateAPIKeyInput{Name: "Sync", UserID: owner.ID, Permissions: []models.Permission{models.PermUsersRead}}
+	_, first, err := testService.CreateAPIKey(owner.ID, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, err := testService.CreateAPIKey(owner.ID, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := doAPIKey(router, "GET", "/api/profile", first); w.Code != http.StatusOK {
+			t.Fatalf("expected request %d to succeed, got %d", i+1, w.Code)
+		}
+	}
+	if w := doAPIKey(router, "GET", "/api/profile", first); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected read limit to be exceeded, got %d", w.Code)
+	}
+
+	// Key khác của cùng user có giới hạn riêng
+	if w := doAPIKey(router, "GET", "/api/profile", second); w.Code != http.StatusOK {
+		t.Errorf("expected other key to be allowed, got %d", w.Code)
+	}
+
+	// Chuỗi key không hợp lệ không tạo bucket riêng mà bị từ chối ở bước xác thực
+	if w := doAPIKey(router, "GET", "/api/profile", first+"x"); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected invalid key to be rejected, got %d", w.Code)
+	}
+}
+
+func TestInvalidCredentialsAreRateLimitedPerIP(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), testPolicies(
+		ratelimit.Policy{Name: ratelimit.PolicyAPI, Limit: 3, Window: time.Minute, Key: ratelimit.KeyIP},
+	)...)
+	router := api.NewServer(testService, api.WithRateLimiter(limiter))
+
+	// Bearer token và API key sai đều bị tính trước khi xác thực
+	attempts := []func() *httptest.ResponseRecorder{
+		func() *httptest.ResponseRecorder { return doAPIKey(router, "GET", "/api/profile", "tg_guess_one") },
+		func() *httptest.ResponseRecorder { return doAPIKey(router, "GET", "/api/profile", "tg_guess_two") },
+		func() *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("GET", "/api/profile", nil)
+			req.RemoteAddr = partnerAddr
+			req.Header.Set("Authorization", "Bearer invalid-token")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		},
+	}
+	for i, attempt := range attempts {
+		if w := attempt(); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d", i+1, w.Code)
+		}
+	}
+	if w := doAPIKey(router, "GET", "/api/profile", "tg_guess_three"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected guessing to be rate limited, got %d", w.Code)
+	}
+}
+
+func TestRateLimitSharedAcrossReplicas(t *testing.T) {
+	server := miniredis.RunT(t)
+	newReplica := func() http.Handler {
+		store, err := ratelimit.NewRedisStore(server.Addr(), "", 0, "tastygo:ratelimit:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		limiter := ratelimit.New(store, testPolicies(
+			ratelimit.Policy{Name: ratelimit.PolicyLogin, Limit: 3, Window: time.Minute, Key: ratelimit.KeyIP},
+		)...)
+		return api.NewServer(testService, api.WithRateLimiter(limiter))
+	}
+	first, second := newReplica(), newReplica()
+
+	loginFrom(first, "198.51.100.7")
+	loginFrom(second, "198.51.100.7")
+	w := loginFrom(first, "198.51.100.7")
+	if w.Code == http.StatusTooManyRequests || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("expected last allowed attempt, got %d with %v", w.Code, w.Header())
+	}
+
+	w = loginFrom(second, "198.51.100.7")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected limit to hold across replicas, got %d", w.Code)
+	}
+	if retry := w.Header().Get("Retry-After"); retry != "20" {
+		t.Errorf("expected Retry-After of 20s, got %q", retry)
+	}
+
+	// Key hết hạn khi đã phục hồi hoàn toàn
+	server.FastForward(time.Minute)
+	if server.Exists("tastygo:ratelimit:login:ip:198.51.100.7") {
+		t.Error("expected rate limit key to expire")
+	}
+}
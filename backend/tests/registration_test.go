@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/mailer"
+)
+
+func TestCustomerRegistration(t *testing.T) {
+	router := api.NewServer(testService)
+
+	register := map[string]string{
+		"email":     "newcustomer@tastygo.com",
+		"username":  "newcustomer",
+		"password":  "Secret#123",
+		"full_name": "New Customer",
+	}
+	w := doJSON(router, "POST", "/api/auth/register", register, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// Email và username trùng trả về 409
+	w = doJSON(router, "POST", "/api/auth/register", register, "")
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected duplicate email to return %d, got %d", http.StatusConflict, w.Code)
+	}
+	w = doJSON(router, "POST", "/api/auth/register", map[string]string{
+		"email":    "other@tastygo.com",
+		"username": "newcustomer",
+		"password": "Secret#123",
+	}, "")
+	if w.Code != http.StatusConflict || decode(w)["error"] != "username is already taken" {
+		t.Errorf("Expected duplicate username to return %d, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+	}
+
+	// Chưa xác thực email thì chưa đăng nhập được
+	w = doJSON(router, "POST", "/api/auth/login", map[string]string{"email": register["email"], "password": register["password"]}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected unverified login to be rejected, got %d", w.Code)
+	}
+
+	msg := waitForMail(t, register["email"])
+	match := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("Expected verification link in email body: %s", msg.Body)
+	}
+
+	w = doJSON(router, "POST", "/api/auth/verify-email", map[string]string{"token": match[1]}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	session := login(t, router, register["email"], register["password"])
+	if session["token"] == nil {
+		t.Error("Expected verified customer to log in")
+	}
+}
+
+// waitForMailCount chờ đến khi địa chỉ to nhận đủ n email và trả về email cuối cùng
+func waitForMailCount(t *testing.T, to string, n int) mailer.Message {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		var received []mailer.Message
+		for _, msg := range testMailer.Messages() {
+			if msg.To == to {
+				received = append(received, msg)
+			}
+		}
+		if len(received) >= n {
+			return received[len(received)-1]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d emails to %s", n, to)
+	return mailer.Message{}
+}
+
+func TestEmailLookupIgnoresCase(t *testing.T) {
+	router := api.NewServer(testService)
+	typed := "Mixed.Case@TastyGo.com"
+	stored := "mixed.case@tastygo.com"
+
+	w := doJSON(router, "POST", "/api/auth/register", map[string]string{
+		"email":    typed,
+		"username": "mixedcase",
+		"password": "Secret#123",
+	}, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	waitForMailCount(t, stored, 1)
+
+	// Gửi lại email xác thực với đúng địa chỉ đã gõ khi đăng ký
+	if w := doJSON(router, "POST", "/api/auth/resend-verification", map[string]string{"email": typed}, ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	msg := waitForMailCount(t, stored, 2)
+	match := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("Expected verification link in email body: %s", msg.Body)
+	}
+	if w := doJSON(router, "POST", "/api/auth/verify-email", map[string]string{"token": match[1]}, ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Đăng nhập và quên mật khẩu với cùng cách gõ hoa thường
+	if session := login(t, router, typed, "Secret#123"); session["token"] == nil {
+		t.Errorf("Expected login with the typed email to succeed, got %v", session)
+	}
+	if w := doJSON(router, "POST", "/api/auth/forgot-password", map[string]string{"email": typed}, ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if msg := waitForMailCount(t, stored, 3); msg.Subject != "TastyGo password reset" {
+		t.Errorf("Expected a password reset email, got %q", msg.Subject)
+	}
+}
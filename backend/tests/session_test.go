@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/tastygo/config"
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/auth"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+// cookieRouter tạo router bật chế độ cookie session
+func cookieRouter() *gin.Engine {
+	return api.NewServer(testService, api.WithSessionCookies(auth.CookieConfig{
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	}))
+}
+
+// doCookie gửi request kèm các cookie và CSRF token (nếu có), không dùng header Authorization
+func doCookie(router http.Handler, method, path string, body interface{}, cookies []*http.Cookie, csrfToken string) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	if csrfToken != "" {
+		req.Header.Set(auth.CSRFHeaderName, csrfToken)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// responseCookies trả về cookie trong response theo tên
+func responseCookies(w *httptest.ResponseRecorder) map[string]*http.Cookie {
+	cookies := make(map[string]*http.Cookie)
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+func TestCookieSessionLogin(t *testing.T) {
+	router := cookieRouter()
+	createUser(t, "cookie@tastygo.com", models.RoleCustomer, "Secret#123")
+
+	w := doJSON(router, "POST", "/api/auth/login", map[string]interface{}{
+		"email":      "cookie@tastygo.com",
+		"password":   "Secret#123",
+		"use_cookie": true,
+	}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Token chỉ nằm trong cookie HttpOnly, body chỉ có CSRF token
+	response := decode(w)
+	if _, ok := response["token"]; ok {
+		t.Fatal("Expected no access token in body in cookie mode")
+	}
+	csrfToken, _ := response["csrf_token"].(string)
+	if csrfToken == "" {
+		t.Fatal("Expected csrf_token in body")
+	}
+
+	cookies := responseCookies(w)
+	for _, name := range []string{auth.AccessCookieName, auth.RefreshCookieName} {
+		cookie := cookies[name]
+		if cookie == nil || !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteStrictMode {
+			t.Fatalf("Expected HttpOnly, Secure, SameSite=Strict cookie %s, got %+v", name, cookie)
+		}
+	}
+	if cookies[auth.RefreshCookieName].Path != "/api/auth/refresh" {
+		t.Errorf("Expected refresh cookie scoped to refresh route, got %q", cookies[auth.RefreshCookieName].Path)
+	}
+	csrfCookie := cookies[auth.CSRFCookieName]
+	if csrfCookie == nil || csrfCookie.HttpOnly || csrfCookie.Value != csrfToken {
+		t.Fatalf("Expected readable CSRF cookie matching body, got %+v", csrfCookie)
+	}
+	session := []*http.Cookie{cookies[auth.AccessCookieName], csrfCookie}
+
+	// Đọc không cần CSRF token
+	if w := doCookie(router, "GET", "/api/profile", nil, session, ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// Thay đổi dữ liệu cần CSRF token trùng với cookie
+	update := map[string]string{"full_name": "Cookie User"}
+	if w := doCookie(router, "PATCH", "/api/profile", update, session, ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected missing CSRF token to be rejected, got %d", w.Code)
+	}
+	if w := doCookie(router, "PATCH", "/api/profile", update, session, "forged"); w.Code != http.StatusForbidden {
+		t.Errorf("Expected wrong CSRF token to be rejected, got %d", w.Code)
+	}
+	if w := doCookie(router, "PATCH", "/api/profile", update, session, csrfToken); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Bearer token vẫn dùng được và không cần CSRF token
+	token := login(t, router, "cookie@tastygo.com", "Secret#123")["token"].(string)
+	if w := doJSON(router, "PATCH", "/api/profile", update, token); w.Code != http.StatusOK {
+		t.Fatalf("Expected bearer request to succeed, got %d", w.Code)
+	}
+}
+
+func TestCookieSessionRefreshAndLogout(t *testing.T) {
+	router := cookieRouter()
+	createUser(t, "cookie-refresh@tastygo.com", models.RoleCustomer, "Secret#123")
+
+	w := doJSON(router, "POST", "/api/auth/login", map[string]interface{}{
+		"email":      "cookie-refresh@tastygo.com",
+		"password":   "Secret#123",
+		"use_cookie": true,
+	}, "")
+	cookies := responseCookies(w)
+	csrfToken := decode(w)["csrf_token"].(string)
+	refresh := []*http.Cookie{cookies[auth.RefreshCookieName], cookies[auth.CSRFCookieName]}
+
+	if w := doCookie(router, "POST", "/api/auth/refresh", nil, refresh, ""); w.Code != http.StatusForbidden {
+		t.Fatalf("Expected refresh without CSRF token to be rejected, got %d", w.Code)
+	}
+
+	w = doCookie(router, "POST", "/api/auth/refresh", nil, refresh, csrfToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	rotated := responseCookies(w)
+	newCSRF := decode(w)["csrf_token"].(string)
+	if rotated[auth.RefreshCookieName] == nil || rotated[auth.RefreshCookieName].Value == cookies[auth.RefreshCookieName].Value {
+		t.Fatal("Expected refresh cookie to be rotated")
+	}
+	if newCSRF == csrfToken {
+		t.Fatal("Expected a new CSRF token after refresh")
+	}
+
+	// Đăng xuất thu hồi phiên và xóa cookie
+	session := []*http.Cookie{rotated[auth.AccessCookieName], rotated[auth.CSRFCookieName]}
+	w = doCookie(router, "POST", "/api/auth/logout", nil, session, newCSRF)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if cleared := responseCookies(w)[auth.AccessCookieName]; cleared == nil || cleared.MaxAge >= 0 {
+		t.Fatalf("Expected access cookie to be cleared, got %+v", cleared)
+	}
+	if w := doCookie(router, "GET", "/api/profile", nil, session, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected revoked session to be rejected, got %d", w.Code)
+	}
+}
+
+func TestCookieSessionDisabled(t *testing.T) {
+	router := api.NewServer(testService)
+	createUser(t, "cookie-disabled@tastygo.com", models.RoleCustomer, "Secret#123")
+
+	w := doJSON(router, "POST", "/api/auth/login", map[string]interface{}{
+		"email":      "cookie-disabled@tastygo.com",
+		"password":   "Secret#123",
+		"use_cookie": true,
+	}, "")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// Cookie bị bỏ qua khi chế độ cookie tắt
+	token := login(t, router, "cookie-disabled@tastygo.com", "Secret#123")["token"].(string)
+	cookie := &http.Cookie{Name: auth.AccessCookieName, Value: token}
+	if w := doCookie(router, "GET", "/api/profile", nil, []*http.Cookie{cookie}, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected cookie to be ignored, got %d", w.Code)
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	router := api.NewServer(testService, api.WithSecurityHeaders(api.SecurityHeaders{
+		HSTSMaxAge:            24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ContentSecurityPolicy: "default-src 'none'",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+	}))
+
+	w := doJSON(router, "GET", "/api/profile", nil, "")
+	expected := map[string]string{
+		"Content-Security-Policy": "default-src 'none'",
+		"X-Frame-Options":         "DENY",
+		"Referrer-Policy":         "no-referrer",
+		"X-Content-Type-Options":  "nosniff",
+	}
+	for name, value := range expected {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("Expected %s %q, got %q", name, value, got)
+		}
+	}
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Expected no HSTS over plain HTTP, got %q", got)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/profile", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=86400; includeSubDomains" {
+		t.Errorf("Expected HSTS behind HTTPS proxy, got %q", got)
+	}
+}
+
+func TestSessionConfigValidation(t *testing.T) {
+	t.Setenv("SESSION_COOKIE_ENABLED", "true")
+	t.Setenv("SESSION_COOKIE_SAMESITE", "none")
+	t.Setenv("SESSION_COOKIE_SECURE", "false")
+
+	_, err := config.Load(nil)
+	verr, ok := err.(*config.ValidationError)
+	if !ok || len(verr.Errors) != 1 {
+		t.Fatalf("Expected SameSite=None without Secure to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/tastygo/config"
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/certs"
+	"github.com/yourusername/tastygo/internal/health"
+)
+
+// testCA là CA tự ký dùng để cấp chứng chỉ server và client trong test
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "TastyGo Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue cấp chứng chỉ có serial cho server (127.0.0.1) hoặc client, trả về cert và key dạng PEM
+func (ca *testCA) issue(t *testing.T, serial int64, client bool) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "tastygo"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if client {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writePEM ghi data vào file name trong dir và trả về đường dẫn
+func writePEM(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// tlsClient tạo client tin CA và dùng chứng chỉ client nếu có; mỗi request mở kết nối mới
+func tlsClient(ca *testCA, clientCert *tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	config := &tls.Config{RootCAs: pool}
+	if clientCert != nil {
+		config.Certificates = []tls.Certificate{*clientCert}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+}
+
+// servedSerial trả về serial của chứng chỉ server nhận được khi gọi url
+func servedSerial(t *testing.T, client *http.Client, url string) int64 {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestTLSCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, 100, false)
+	certFile := writePEM(t, dir, "tls.crt", certPEM)
+	keyFile := writePEM(t, dir, "tls.key", keyPEM)
+
+	reloader, err := certs.NewReloader(certFile, keyFile, "", tls.VersionTLS12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = reloader.TLSConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	client := tlsClient(ca, nil)
+	if serial := servedSerial(t, client, server.URL); serial != 100 {
+		t.Fatalf("Expected certificate 100, got %d", serial)
+	}
+
+	// File không đổi thì không nạp lại
+	if reloaded, err := reloader.Reload(); err != nil || reloaded {
+		t.Fatalf("Expected no reload for unchanged files, got %v %v", reloaded, err)
+	}
+
+	// File hỏng (ví dụ đang được ghi dở) giữ nguyên chứng chỉ cũ
+	writePEM(t, dir, "tls.key", []byte("garbage"))
+	if _, err := reloader.Reload(); err == nil {
+		t.Fatal("Expected invalid key to fail")
+	}
+	if serial := servedSerial(t, client, server.URL); serial != 100 {
+		t.Fatalf("Expected certificate 100 to stay after failed reload, got %d", serial)
+	}
+
+	// Chứng chỉ mới được dùng cho các kết nối sau mà không cần khởi động lại
+	certPEM, keyPEM = ca.issue(t, 200, false)
+	writePEM(t, dir, "tls.crt", certPEM)
+	writePEM(t, dir, "tls.key", keyPEM)
+	if reloaded, err := reloader.Reload(); err != nil || !reloaded {
+		t.Fatalf("Expected reload, got %v %v", reloaded, err)
+	}
+	if serial := servedSerial(t, client, server.URL); serial != 200 {
+		t.Fatalf("Expected certificate 200 after reload, got %d", serial)
+	}
+}
+
+func TestMutualTLSInternalRoutes(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, 1, false)
+	reloader, err := certs.NewReloader(
+		writePEM(t, dir, "tls.crt", certPEM),
+		writePEM(t, dir, "tls.key", keyPEM),
+		writePEM(t, dir, "ca.crt", ca.pem),
+		tls.VersionTLS12,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := api.NewServer(testService, api.WithClientCertRoutes("/version"))
+	api.RegisterProbes(router, health.NewRegistry(time.Second))
+	server := httptest.NewUnstartedServer(router)
+	server.TLS = reloader.TLSConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(client *http.Client, path string) int {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Không có chứng chỉ client: route công khai vẫn dùng được, route nội bộ bị từ chối
+	anonymous := tlsClient(ca, nil)
+	if status := get(anonymous, "/version"); status != http.StatusForbidden {
+		t.Fatalf("Expected 403 without client certificate, got %d", status)
+	}
+	if status := get(anonymous, "/api/profile"); status != http.StatusUnauthorized {
+		t.Fatalf("Expected public route to skip mTLS, got %d", status)
+	}
+
+	clientPEM, clientKeyPEM := ca.issue(t, 2, true)
+	clientCert, err := tls.X509KeyPair(clientPEM, clientKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := get(tlsClient(ca, &clientCert), "/version"); status != http.StatusOK {
+		t.Fatalf("Expected 200 with client certificate, got %d", status)
+	}
+
+	// Chứng chỉ client do CA khác ký bị từ chối ngay khi handshake
+	otherPEM, otherKeyPEM := newTestCA(t).issue(t, 3, true)
+	otherCert, _ := tls.X509KeyPair(otherPEM, otherKeyPEM)
+	if _, err := tlsClient(ca, &otherCert).Get(server.URL + "/version"); err == nil {
+		t.Fatal("Expected certificate from unknown CA to be rejected")
+	}
+}
+
+func TestHTTPSRedirect(t *testing.T) {
+	cases := []struct {
+		port     int
+		url      string
+		location string
+	}{
+		{8443, "http://api.tastygo.vn:8080/api/profile?x=1", "https://api.tastygo.vn:8443/api/profile?x=1"},
+		{443, "http://api.tastygo.vn/api/auth/login", "https://api.tastygo.vn/api/auth/login"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("POST", tc.url, nil)
+		w := httptest.NewRecorder()
+		api.HTTPSRedirectHandler(tc.port).ServeHTTP(w, req)
+		if w.Code != http.StatusPermanentRedirect {
+			t.Errorf("Expected 308, got %d", w.Code)
+		}
+		if got := w.Header().Get("Location"); got != tc.location {
+			t.Errorf("Expected Location %q, got %q", tc.location, got)
+		}
+	}
+}
+
+func TestTLSConfigValidation(t *testing.T) {
+	t.Setenv("TLS_CERT_FILE", filepath.Join(t.TempDir(), "missing.crt"))
+	t.Setenv("TLS_REDIRECT_PORT", "80")
+
+	// Thiếu key, file chứng chỉ không tồn tại, redirect cần TLS
+	_, err := config.Load(nil)
+	verr, ok := err.(*config.ValidationError)
+	if !ok || len(verr.Errors) != 3 {
+		t.Fatalf("Expected 3 validation errors, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/tastygo/internal/api"
+	"github.com/yourusername/tastygo/internal/models"
+)
+
+func TestUserCRUD(t *testing.T) {
+	router := api.NewServer(testService)
+	admin := loginSuperAdmin(t, router)["token"].(string)
+	user := createUser(t, "crud@tastygo.com", models.RoleCustomer, "Secret#123")
+	path := fmt.Sprintf("/api/admin/users/%d", user.ID)
+
+	w := doJSON(router, "GET", path, nil, admin)
+	if w.Code != http.StatusOK || decode(w)["email"] != "crud@tastygo.com" {
+		t.Fatalf("Expected user detail, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Cập nhật email, role và profile
+	w = doJSON(router, "PATCH", path, map[string]interface{}{
+		"email":   "crud-updated@tastygo.com",
+		"role":    "admin",
+		"profile": map[string]string{"phone": "0900000000"},
+	}, admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Log phải chứa diff trước/sau
+	var entry models.ActivityLog
+	testDB.Where("activity_type = ?", models.ActivityUpdateUser).Order("id DESC").First(&entry)
+	if entry.Changes == "" {
+		t.Fatal("Expected activity log to record changes")
+	}
+	for _, field := range []string{`"email"`, `"role"`, `"phone"`} {
+		if !strings.Contains(string(entry.Changes), field) {
+			t.Errorf("Expected changes to contain %s, got %s", field, entry.Changes)
+		}
+	}
+
+	// Trùng email với user khác trả về 409
+	w = doJSON(router, "PATCH", path, map[string]interface{}{"email": "superadmin@tastygo.com"}, admin)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected duplicate email to return %d, got %d", http.StatusConflict, w.Code)
+	}
+
+	// Lọc theo role và tìm kiếm
+	w = doJSON(router, "GET", "/api/admin/users?role=admin&search=crud-updated", nil, admin)
+	if data := decode(w)["data"].([]interface{}); len(data) != 1 {
+		t.Errorf("Expected 1 filtered user, got %d", len(data))
+	}
+
+	// Xóa mềm rồi khôi phục
+	if w := doJSON(router, "DELETE", path, nil, admin); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	w = doJSON(router, "GET", "/api/admin/users?deleted=true&search=crud-updated", nil, admin)
+	if data := decode(w)["data"].([]interface{}); len(data) != 1 {
+		t.Errorf("Expected deleted user in deleted listing, got %d", len(data))
+	}
+	if w := doJSON(router, "POST", path+"/restore", nil, admin); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := doJSON(router, "POST", path+"/restore", nil, admin); w.Code != http.StatusConflict {
+		t.Errorf("Expected restoring active user to conflict, got %d", w.Code)
+	}
+
+	// Không thể sửa SuperAdmin
+	var superAdmin models.User
+	testDB.Where("role = ?", models.RoleSuperAdmin).First(&superAdmin)
+	w = doJSON(router, "PATCH", fmt.Sprintf("/api/admin/users/%d", superAdmin.ID), map[string]interface{}{"username": "x"}, admin)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected superadmin update to be forbidden, got %d", w.Code)
+	}
+}